@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/books"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/playlist"
+	"github.com/spf13/cobra"
+)
+
+var downloadBookCmd = &cobra.Command{
+	Use:   "book",
+	Short: "Download a book (epub, pdf, cbz, ...) or every book in a folder",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bookID, err := resolveItemID(cmd, args, "Book")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+		opts.Profile = nil
+
+		item, err := client.GetItem(ctx, bookID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		items := []api.Item{*item}
+		if item.Type != "Book" {
+			items, err = client.BookItems(ctx, bookID, []string{"Book"})
+			if err != nil {
+				return exitError(4, err)
+			}
+		}
+		if len(items) == 0 {
+			printInfo("No books found\n")
+			return nil
+		}
+		return runDownloadItems(client, storeDir, items, opts)
+	},
+}
+
+var downloadAudiobookCmd = &cobra.Command{
+	Use:   "audiobook",
+	Short: "Download an audiobook with all of its files in chapter order",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bookID, err := resolveItemID(cmd, args, "Audiobook")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+		opts.Profile = nil
+
+		book, err := client.GetItem(ctx, bookID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		chapters := []api.Item{*book}
+		if book.Type != "AudioBook" && book.Type != "Audio" {
+			chapters, err = client.BookItems(ctx, bookID, []string{"AudioBook", "Audio"})
+			if err != nil {
+				return exitError(4, err)
+			}
+		}
+		if len(chapters) == 0 {
+			printInfo("No audiobook files found\n")
+			return nil
+		}
+		return runDownloadAudiobook(client, storeDir, *book, chapters, opts)
+	},
+}
+
+func init() {
+	downloadBookCmd.Flags().String("id", "", "Book or folder item ID")
+	downloadAudiobookCmd.Flags().String("id", "", "Audiobook item or folder ID")
+	downloadCmd.AddCommand(downloadBookCmd)
+	downloadCmd.AddCommand(downloadAudiobookCmd)
+}
+
+// buildBookPath lays books out as "Books/<Author>/<Series>/<NN - Title>.ext".
+func buildBookPath(root string, item api.Item) string {
+	dir := filepath.Join(root, "Books", download.SanitizePathSegment(books.Author(item)))
+	if item.SeriesName != "" {
+		dir = filepath.Join(dir, download.SanitizePathSegment(item.SeriesName))
+	}
+	return filepath.Join(dir, download.SanitizePathSegment(books.BookFileName(item)+bookExtension(item)))
+}
+
+func bookExtension(item api.Item) string {
+	if ext := filepath.Ext(item.Path); ext != "" {
+		return ext
+	}
+	return ".epub"
+}
+
+func audiobookExtension(item api.Item) string {
+	if ext := filepath.Ext(item.PrimarySource().Path); ext != "" {
+		return ext
+	}
+	return ".mp3"
+}
+
+// runDownloadAudiobook downloads the files of an audiobook into
+// "Audiobooks/<Author>/<Title>/" and writes cover art plus, for multi-file
+// books, an .m3u8 that plays the chapters in order.
+func runDownloadAudiobook(client *api.Client, storeDir string, book api.Item, chapters []api.Item, opts downloadOptions) error {
+	storeDB, outputDir, limiter, err := openDownloadTarget(storeDir, opts)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	author := books.Author(book)
+	if author == "Unknown Author" {
+		author = books.Author(chapters[0])
+	}
+	folder := filepath.Join(outputDir, "Audiobooks", download.SanitizePathSegment(author), download.SanitizePathSegment(book.Name))
+
+	var entries []playlist.Entry
+	for i, chapter := range chapters {
+		name := book.Name
+		if len(chapters) > 1 {
+			name = books.ChapterFileName(i+1, chapter.Name)
+		}
+		path := filepath.Join(folder, download.SanitizePathSegment(name+audiobookExtension(chapter)))
+		entries = append(entries, playlist.Entry{Path: path, Title: chapter.Name, Duration: int(chapter.RunTimeTicks / ticksPerSecond)})
+
+		done, err := alreadyDownloaded(storeDB, chapter.Id)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+		chapterOpts := opts
+		chapterOpts.OverridePath = path
+		if len(chapters) > 1 {
+			chapterOpts.Parent = book.Id
+		}
+		if err := downloadItem(client, storeDB, chapter, outputDir, limiter, chapterOpts); err != nil {
+			return err
+		}
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if opts.CoverArt {
+		cover := filepath.Join(folder, "cover.jpg")
+		if _, err := os.Stat(cover); os.IsNotExist(err) {
+			if err := saveImage(client, book.Id, "Primary", cover); err != nil {
+				printError("cover art for %s failed: %v\n", book.Name, err)
+			}
+		}
+	}
+	if len(chapters) > 1 {
+		var buf bytes.Buffer
+		if err := playlist.WriteM3U8(&buf, folder, entries); err != nil {
+			return err
+		}
+		return saveResponse(&buf, filepath.Join(folder, download.SanitizePathSegment(book.Name+".m3u8")))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/nfo"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var collectionMeta bool
+
+var downloadCollectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Download every movie of a collection (BoxSet)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		collectionID, err := resolveItemID(cmd, args, "Collection")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		collection, err := client.GetItem(ctx, collectionID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		children, err := client.CollectionItems(ctx, collectionID)
+		if err != nil {
+			return exitError(4, err)
+		}
+
+		var movies []api.Item
+		for _, child := range children {
+			if child.Type != "Movie" {
+				printInfo("Skipping %s (%s)\n", child.Name, child.Type)
+				continue
+			}
+			movies = append(movies, child)
+		}
+		if len(movies) == 0 {
+			printInfo("No movies in collection\n")
+			return nil
+		}
+
+		if err := runDownloadItems(client, storeDir, movies, opts); err != nil {
+			return err
+		}
+		if collectionMeta && !opts.DryRun {
+			return writeCollectionMeta(client, storeDir, *collection, movies, opts)
+		}
+		return nil
+	},
+}
+
+func init() {
+	downloadCollectionCmd.Flags().String("id", "", "Collection (BoxSet) item ID")
+	downloadCollectionCmd.Flags().BoolVar(&collectionMeta, "collection-meta", false, "Write collection artwork and collection.nfo to <output>/Collections/<name>")
+	downloadCmd.AddCommand(downloadCollectionCmd)
+}
+
+// writeCollectionMeta saves the collection poster, fanart and a
+// collection.nfo listing the member movies and their local files.
+func writeCollectionMeta(client *api.Client, storeDir string, collection api.Item, movies []api.Item, opts downloadOptions) error {
+	storeDB, err := store.Open(storeDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	folder := filepath.Join(resolveOutputDir(storeDir, opts), "Collections", download.SanitizeFileName(collection.Name))
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return err
+	}
+
+	info := nfo.Collection{Title: collection.Name, Plot: collection.Overview}
+	for _, movie := range movies {
+		entry := nfo.CollectionMovie{Title: movie.Name, Year: movie.ProductionYear}
+		files, err := storeDB.ItemFiles(movie.Id)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			if rel, err := filepath.Rel(folder, files[0]); err == nil {
+				entry.File = filepath.ToSlash(rel)
+			}
+		}
+		info.Movies = append(info.Movies, entry)
+	}
+
+	var buf bytes.Buffer
+	if err := nfo.Write(&buf, info); err != nil {
+		return err
+	}
+	if err := saveResponse(&buf, filepath.Join(folder, "collection.nfo")); err != nil {
+		return err
+	}
+
+	artwork := []struct{ imageType, name string }{
+		{imageType: "Primary", name: "poster.jpg"},
+		{imageType: "Backdrop", name: "fanart.jpg"},
+	}
+	for _, art := range artwork {
+		if err := saveImage(client, collection.Id, art.imageType, filepath.Join(folder, art.name)); err != nil {
+			printError("collection %s image unavailable: %v\n", art.imageType, err)
+		}
+	}
+	printInfo("Wrote collection metadata to %s\n", folder)
+	return nil
+}
@@ -14,9 +14,13 @@ import (
 	"time"
 
 	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
 	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/episodes"
 	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/transcode"
 	"github.com/julianfbeck/jellyfin-download-cli/internal/ui"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/versions"
 	"github.com/spf13/cobra"
 	"golang.org/x/time/rate"
 )
@@ -55,16 +59,23 @@ var downloadMovieCmd = &cobra.Command{
 			}
 		}
 
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
 		item, err := client.GetItem(ctx, movieID)
 		if err != nil {
 			return exitError(4, err)
 		}
 
-		return runDownloadItems(client, storeDir, []api.Item{*item}, downloadOptions{
-			Rate:   resolveRate(cfg.DefaultRate),
-			Output: downloadOutput,
-			DryRun: dryRun,
-		})
+		if err := runDownloadItems(client, storeDir, []api.Item{*item}, opts); err != nil {
+			return err
+		}
+		if downloadExtrasFlag {
+			return runDownloadExtras(client, storeDir, *item, opts)
+		}
+		return nil
 	},
 }
 
@@ -75,17 +86,48 @@ var (
 	downloadAll  bool
 	seriesSelect bool
 	movieSelect  bool
+	episodeSpec  string
+
+	watchUnplayed   bool
+	watchPlayed     bool
+	watchFavorites  bool
+	watchInProgress bool
 )
 
 var downloadSeriesCmd = &cobra.Command{
 	Use:   "series",
 	Short: "Download a series",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		seasons, err := parseNumberList(seasonList)
+		if err != nil {
+			return exitError(2, fmt.Errorf("--season: %w", err))
+		}
+		episodesFilter, err := parseNumberList(episodeList)
+		if err != nil {
+			return exitError(2, fmt.Errorf("--episode: %w", err))
+		}
+		selector, err := episodes.Parse(episodeSpec)
+		if err != nil {
+			return exitError(2, fmt.Errorf("--episodes: %w", err))
+		}
+		if selector != nil && (seasonList != "" || episodeList != "") {
+			return exitError(2, fmt.Errorf("--episodes cannot be combined with --season/--episode"))
+		}
+		watch := watchFilter{Unplayed: watchUnplayed, Played: watchPlayed, Favorites: watchFavorites, InProgress: watchInProgress}
+		if watch.Unplayed && watch.Played {
+			return exitError(2, fmt.Errorf("--played and --unplayed cannot be combined"))
+		}
+
 		client, cfg, storeDir, err := getClient(true)
 		if err != nil {
 			return err
 		}
 
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
 		id := seriesID
 		if id == "" && seriesSelect && !noInput {
 			id, err = promptSelectSeries(client)
@@ -97,29 +139,35 @@ var downloadSeriesCmd = &cobra.Command{
 			return exitError(2, fmt.Errorf("series id required (use --id or --select)"))
 		}
 
-		episodes, err := client.SeriesEpisodes(ctx, id)
+		items, err := client.SeriesEpisodes(ctx, id)
 		if err != nil {
 			return exitError(4, err)
 		}
-		if len(episodes) == 0 {
+		if len(items) == 0 {
 			printInfo("No episodes found\n")
 			return nil
 		}
 
-		seasons := parseNumberList(seasonList)
-		episodesFilter := parseNumberList(episodeList)
+		if absoluteNumbers {
+			opts.Naming.Absolute = episodes.AbsoluteNumbers(items)
+		}
 
-		filtered := filterEpisodes(episodes, seasons, episodesFilter)
+		filtered := filterEpisodes(items, seasons, episodesFilter)
+		if selector != nil {
+			filtered = selector.Select(filtered, episodes.AbsoluteNumbers(items))
+		}
+		filtered = watch.apply(filtered)
 		if len(filtered) == 0 {
 			printInfo("No episodes matched filters\n")
 			return nil
 		}
 
-		if noInput && !downloadAll && seasonList == "" && episodeList == "" && !dryRun {
-			return exitError(2, fmt.Errorf("non-interactive mode requires --all or --season/--episode filters"))
+		hasFilters := seasonList != "" || episodeList != "" || selector != nil || watch.active()
+		if noInput && !downloadAll && !hasFilters && !dryRun {
+			return exitError(2, fmt.Errorf("non-interactive mode requires --all or --season/--episode/--episodes filters"))
 		}
 
-		if !downloadAll && seasonList == "" && episodeList == "" && !dryRun {
+		if !downloadAll && !hasFilters && !dryRun {
 			if !noInput {
 				ok, err := confirmPrompt("Download all episodes? [y/N]: ")
 				if err != nil {
@@ -131,12 +179,18 @@ var downloadSeriesCmd = &cobra.Command{
 			}
 		}
 
-		return runDownloadItems(client, storeDir, filtered, downloadOptions{
-			Rate:   resolveRate(cfg.DefaultRate),
-			Output: downloadOutput,
-			DryRun: dryRun,
-			Series: id,
-		})
+		opts.Series = id
+		if err := runDownloadItems(client, storeDir, filtered, opts); err != nil {
+			return err
+		}
+		if downloadExtrasFlag {
+			series, err := client.GetItem(ctx, id)
+			if err != nil {
+				return exitError(4, err)
+			}
+			return runDownloadExtras(client, storeDir, *series, opts)
+		}
+		return nil
 	},
 }
 
@@ -153,16 +207,26 @@ var downloadEpisodeCmd = &cobra.Command{
 			return err
 		}
 
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
 		item, err := client.GetItem(ctx, itemID)
 		if err != nil {
 			return exitError(4, err)
 		}
 
-		return runDownloadItems(client, storeDir, []api.Item{*item}, downloadOptions{
-			Rate:   resolveRate(cfg.DefaultRate),
-			Output: downloadOutput,
-			DryRun: dryRun,
-		})
+		if absoluteNumbers && item.SeriesId != "" {
+			all, err := client.SeriesEpisodes(ctx, item.SeriesId)
+			if err != nil {
+				return exitError(4, err)
+			}
+			opts.Naming.Absolute = episodes.AbsoluteNumbers(all)
+		}
+
+		opts.Series = item.SeriesId
+		return runDownloadItems(client, storeDir, []api.Item{*item}, opts)
 	},
 }
 
@@ -170,6 +234,10 @@ func init() {
 	downloadCmd.PersistentFlags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
 	downloadCmd.PersistentFlags().StringVar(&downloadOutput, "output", "", "Output directory (default: store/downloads)")
 	downloadCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show planned downloads without downloading")
+	downloadCmd.PersistentFlags().StringVar(&layoutMode, "layout", "", "Folder layout: plex (default) or mirror (server folder structure)")
+	downloadCmd.PersistentFlags().StringVar(&specialsFolder, "specials-folder", "", "Folder name for season 0 specials (default: Season 00)")
+	downloadCmd.PersistentFlags().StringVar(&episodeTemplate, "episode-template", "", "Go template for episode file names (e.g. '{{.Series}} - S{{printf \"%02d\" .Season}}E{{printf \"%02d\" .Episode}}')")
+	downloadCmd.PersistentFlags().StringVar(&movieTemplate, "movie-template", "", "Go template for movie file and folder names (e.g. '{{.Name}} ({{.Year}})')")
 
 	downloadMovieCmd.Flags().String("id", "", "Movie item ID")
 	downloadMovieCmd.Flags().BoolVar(&movieSelect, "select", false, "Interactively select a movie")
@@ -178,10 +246,17 @@ func init() {
 	downloadSeriesCmd.Flags().StringVar(&seriesID, "id", "", "Series item ID")
 	downloadSeriesCmd.Flags().StringVar(&seasonList, "season", "", "Season numbers (e.g. 1,2,3-5)")
 	downloadSeriesCmd.Flags().StringVar(&episodeList, "episode", "", "Episode numbers (e.g. 1,2,3-5)")
+	downloadSeriesCmd.Flags().StringVar(&episodeSpec, "episodes", "", "Episode selector: S01E05-S02E03, S03, S02E01,S02E04, 25-30 (absolute), first:3, latest:5, after:S04E10")
 	downloadSeriesCmd.Flags().BoolVar(&downloadAll, "all", false, "Download all episodes")
+	downloadSeriesCmd.Flags().BoolVar(&watchUnplayed, "unplayed", false, "Only episodes you have not watched")
+	downloadSeriesCmd.Flags().BoolVar(&watchPlayed, "played", false, "Only episodes you have watched")
+	downloadSeriesCmd.Flags().BoolVar(&watchFavorites, "favorites", false, "Only episodes marked as favorite")
+	downloadSeriesCmd.Flags().BoolVar(&watchInProgress, "in-progress", false, "Only episodes you started but did not finish")
 	downloadSeriesCmd.Flags().BoolVar(&seriesSelect, "select", false, "Interactively select a series")
+	downloadSeriesCmd.Flags().BoolVar(&absoluteNumbers, "absolute", false, "Name episodes by absolute number (anime)")
 
 	downloadEpisodeCmd.Flags().String("id", "", "Episode item ID")
+	downloadEpisodeCmd.Flags().BoolVar(&absoluteNumbers, "absolute", false, "Name the episode by absolute number (anime)")
 	_ = downloadEpisodeCmd.Flags().Lookup("id")
 
 	downloadCmd.AddCommand(downloadMovieCmd)
@@ -196,6 +271,53 @@ type downloadOptions struct {
 	DryRun       bool
 	Series       string
 	OverridePath string
+	Naming       namingOptions
+	Subtitles    subtitleOptions
+	Profile      *transcode.Profile
+	Version      *versions.Selector
+	Parent       string
+	Part         int
+	Lyrics       bool
+	CoverArt     bool
+	// QueueFirst records every item as queued before downloading so an
+	// interrupted run can be picked up by `downloads resume`.
+	QueueFirst bool
+	// SkipDone leaves items alone that already have a complete download,
+	// for commands that are re-run to pick up changes on the server.
+	SkipDone bool
+}
+
+func newDownloadOptions(cfg *config.Config) (downloadOptions, error) {
+	naming, err := resolveNaming(cfg)
+	if err != nil {
+		return downloadOptions{}, err
+	}
+	subs, err := resolveSubtitles()
+	if err != nil {
+		return downloadOptions{}, err
+	}
+	profile, err := resolveProfile()
+	if err != nil {
+		return downloadOptions{}, err
+	}
+	version, err := resolveVersion()
+	if err != nil {
+		return downloadOptions{}, err
+	}
+	if profile != nil {
+		naming.Extension = profile.Extension()
+	}
+	return downloadOptions{
+		Rate:      resolveRate(cfg.DefaultRate),
+		Output:    downloadOutput,
+		DryRun:    dryRun,
+		Naming:    naming,
+		Subtitles: subs,
+		Profile:   profile,
+		Version:   version,
+		Lyrics:    downloadLyrics,
+		CoverArt:  !noCoverArt,
+	}, nil
 }
 
 func resolveRate(defaultRate string) string {
@@ -220,44 +342,117 @@ func resolveItemID(cmd *cobra.Command, args []string, itemType string) (string,
 }
 
 func runDownloadItems(client *api.Client, storeDir string, items []api.Item, opts downloadOptions) error {
-	storeDB, err := store.Open(storeDir)
+	storeDB, outputDir, limiter, err := openDownloadTarget(storeDir, opts)
 	if err != nil {
 		return err
 	}
 	defer storeDB.Close()
+	opts.Naming.MultiDiscAlbums = albumDiscs(client, items, opts.Naming.MultiDiscAlbums)
 
-	outputDir := opts.Output
-	if outputDir == "" {
-		outputDir = filepath.Join(storeDir, "downloads")
-	}
-	if err := os.MkdirAll(outputDir, 0700); err != nil {
-		return err
+	if opts.QueueFirst && !opts.DryRun {
+		if err := queueDownloads(client, storeDB, items, outputDir, opts); err != nil {
+			return err
+		}
 	}
 
-	limiter, err := download.ParseRateLimit(opts.Rate)
-	if err != nil {
-		return exitError(2, err)
+	for _, item := range items {
+		if item.Type == "Movie" && item.PartCount > 1 {
+			err = downloadMovieParts(client, storeDB, item, outputDir, limiter, opts)
+		} else {
+			err = downloadItem(client, storeDB, item, outputDir, limiter, opts)
+		}
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+func queueDownloads(client *api.Client, storeDB *store.Store, items []api.Item, outputDir string, opts downloadOptions) error {
+	queued := 0
 	for _, item := range items {
-		if err := downloadItem(client, storeDB, item, outputDir, limiter, opts); err != nil {
+		if item.Type == "Movie" && item.PartCount > 1 {
+			// Parts are recorded individually when they are downloaded.
+			continue
+		}
+		done, err := alreadyDownloaded(storeDB, item.Id)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+		item, err := applyVersion(item, opts.Version)
+		if err != nil {
+			return exitError(2, err)
+		}
+		record := &store.Download{
+			ItemID:   item.Id,
+			ItemName: item.Name,
+			ItemType: item.Type,
+			SeriesID: sqlNullString(item.SeriesId),
+			Path:     buildItemPath(client, outputDir, item, opts.Naming),
+			Status:   "queued",
+			Version:  sqlNullString(versionName(item)),
+		}
+		if opts.Profile != nil {
+			record.Profile = sqlNullString(opts.Profile.String())
+		}
+		setDownloadSource(record, item)
+		if _, err := storeDB.UpsertDownload(record); err != nil {
 			return err
 		}
+		queued++
 	}
+	printInfo("Queued %d of %d items\n", queued, len(items))
 	return nil
 }
 
+func resolveOutputDir(storeDir string, opts downloadOptions) string {
+	if opts.Output != "" {
+		return opts.Output
+	}
+	return filepath.Join(storeDir, "downloads")
+}
+
+func openDownloadTarget(storeDir string, opts downloadOptions) (*store.Store, string, *rate.Limiter, error) {
+	outputDir := resolveOutputDir(storeDir, opts)
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return nil, "", nil, err
+	}
+
+	limiter, err := download.ParseRateLimit(opts.Rate)
+	if err != nil {
+		return nil, "", nil, exitError(2, err)
+	}
+
+	storeDB, err := store.Open(storeDir)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return storeDB, outputDir, limiter, nil
+}
+
 func downloadItem(client *api.Client, storeDB *store.Store, item api.Item, outputDir string, limiter *rate.Limiter, opts downloadOptions) error {
+	item, err := applyVersion(item, opts.Version)
+	if err != nil {
+		return exitError(2, err)
+	}
+
 	path := opts.OverridePath
 	if path == "" {
-		path = buildDefaultPath(outputDir, item)
+		path = buildItemPath(client, outputDir, item, opts.Naming)
 	}
 
+	seriesID := opts.Series
+	if seriesID == "" && item.Type == "Episode" {
+		seriesID = item.SeriesId
+	}
 	record := &store.Download{
 		ItemID:   item.Id,
 		ItemName: item.Name,
 		ItemType: item.Type,
-		SeriesID: sqlNullString(opts.Series),
+		SeriesID: sqlNullString(seriesID),
 	}
 	if item.ParentIndexNumber != 0 {
 		record.SeasonNumber = sqlNullInt(item.ParentIndexNumber)
@@ -266,6 +461,24 @@ func downloadItem(client *api.Client, storeDB *store.Store, item api.Item, outpu
 		record.EpisodeNumber = sqlNullInt(item.IndexNumber)
 	}
 	record.Path = path
+	if opts.Profile != nil {
+		record.Profile = sqlNullString(opts.Profile.String())
+	}
+	record.Version = sqlNullString(versionName(item))
+	record.ParentID = sqlNullString(opts.Parent)
+	record.Part = sqlNullInt(opts.Part)
+	setDownloadSource(record, item)
+
+	if opts.SkipDone {
+		done, err := downloadedWithProfile(storeDB, item.Id, record.Profile.String)
+		if err != nil {
+			return err
+		}
+		if done {
+			printInfo("Already downloaded %s\n", item.Name)
+			return nil
+		}
+	}
 
 	id, err := storeDB.UpsertDownload(record)
 	if err != nil {
@@ -286,11 +499,16 @@ func downloadItem(client *api.Client, storeDB *store.Store, item api.Item, outpu
 	}
 
 	offset := existingFileSize(path)
+	if offset > 0 && opts.Profile != nil {
+		// Transcoded streams cannot be resumed at a byte offset.
+		printInfo("Restarting transcoded download %s\n", item.Name)
+		offset = 0
+	}
 	if offset > 0 {
 		printInfo("Resuming %s (%d bytes)\n", item.Name, offset)
 	}
 
-	resp, err := client.OpenDownload(ctx, item.Id, offset)
+	resp, err := openItemStream(client, item, offset, opts)
 	if err != nil {
 		_ = storeDB.SetDownloadStatus(id, "failed", err.Error())
 		return exitError(5, err)
@@ -302,11 +520,13 @@ func downloadItem(client *api.Client, storeDB *store.Store, item api.Item, outpu
 		offset = 0
 	}
 
-	if opts.OverridePath == "" {
+	if opts.OverridePath == "" && opts.Naming.Layout != layoutMirror && opts.Profile == nil && !keepsGeneratedName(item, opts.Naming) {
 		if filename := filenameFromResponse(resp); filename != "" {
-			path = filepath.Join(filepath.Dir(path), download.SanitizeFileName(filename))
-			record.Path = path
-			_, _ = storeDB.UpsertDownload(record)
+			renamed := filepath.Join(filepath.Dir(path), download.SanitizeFileName(filename))
+			if renamed != path && storeDB.UpdateDownloadPath(id, renamed) == nil {
+				path = renamed
+				record.Path = path
+			}
 		}
 	}
 
@@ -331,16 +551,24 @@ func downloadItem(client *api.Client, storeDB *store.Store, item api.Item, outpu
 		}
 	}
 
-	_, err = download.CopyWithProgress(ctx, f, resp.Body, bytesTotal, limiter, progressFn)
+	written, err := download.CopyWithProgress(ctx, f, resp.Body, bytesTotal, limiter, progressFn)
 	if err != nil {
 		_ = storeDB.SetDownloadStatus(id, "failed", err.Error())
 		return exitError(5, err)
 	}
 
-	_ = storeDB.UpdateDownloadProgress(id, bytesTotal, bytesTotal)
+	// Transcoded streams report no length up front, so record what was
+	// actually written.
+	_ = storeDB.UpdateDownloadProgress(id, offset+written, offset+written)
 	_ = storeDB.SetDownloadStatus(id, "done", "")
 	if item.Type == "Episode" {
-		_ = storeDB.UpdateSeriesProgress(opts.Series, int64(item.ParentIndexNumber), int64(item.IndexNumber))
+		_ = storeDB.UpdateSeriesProgress(seriesID, int64(item.ParentIndexNumber), int64(item.IndexNumber))
+	}
+	if opts.Subtitles.Selection.Enabled() {
+		downloadSubtitles(client, item, path, opts.Subtitles)
+	}
+	if item.Type == "Audio" {
+		afterTrackDownload(client, item, path, opts)
 	}
 
 	if !quietMode {
@@ -424,10 +652,50 @@ func filterEpisodes(items []api.Item, seasons, episodes []int) []api.Item {
 	return filtered
 }
 
-func parseNumberList(value string) []int {
+// watchFilter selects episodes by the user's watch state. All enabled
+// conditions must hold.
+type watchFilter struct {
+	Unplayed   bool
+	Played     bool
+	Favorites  bool
+	InProgress bool
+}
+
+func (f watchFilter) active() bool {
+	return f.Unplayed || f.Played || f.Favorites || f.InProgress
+}
+
+func (f watchFilter) apply(items []api.Item) []api.Item {
+	if !f.active() {
+		return items
+	}
+	var out []api.Item
+	for _, item := range items {
+		data := item.UserData
+		if data == nil {
+			data = &api.UserData{}
+		}
+		if f.Unplayed && data.Played {
+			continue
+		}
+		if f.Played && !data.Played {
+			continue
+		}
+		if f.Favorites && !data.IsFavorite {
+			continue
+		}
+		if f.InProgress && !data.InProgress() {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func parseNumberList(value string) ([]int, error) {
 	value = strings.TrimSpace(value)
 	if value == "" {
-		return nil
+		return nil, nil
 	}
 	parts := strings.Split(value, ",")
 	var out []int
@@ -440,19 +708,24 @@ func parseNumberList(value string) []int {
 			bounds := strings.SplitN(part, "-", 2)
 			start, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
 			end, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
-			if err1 != nil || err2 != nil || start > end {
-				continue
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range %q: end is before start", part)
 			}
 			for i := start; i <= end; i++ {
 				out = append(out, i)
 			}
 			continue
 		}
-		if v, err := strconv.Atoi(part); err == nil {
-			out = append(out, v)
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
 		}
+		out = append(out, v)
 	}
-	return out
+	return out, nil
 }
 
 func toSet(values []int) map[int]struct{} {
@@ -474,18 +747,19 @@ func confirmPrompt(label string) (bool, error) {
 	return line == "y" || line == "yes", nil
 }
 
-func buildItemFilename(item api.Item) string {
+func buildItemFilename(item api.Item, naming namingOptions) string {
+	fields := itemNameFields(item, naming)
 	if item.Type == "Episode" {
-		series := item.SeriesName
-		if series == "" {
-			series = "Series"
+		if name := executeNameTemplate(naming.EpisodeTemplate, fields); name != "" {
+			return name
 		}
-		season := item.ParentIndexNumber
-		ep := item.IndexNumber
-		if season > 0 && ep > 0 {
-			return fmt.Sprintf("%s - S%02dE%02d - %s", series, season, ep, item.Name)
+		if tag := episodes.Tag(fields.Season, fields.Episode, fields.EpisodeEnd, fields.Absolute, fields.AirDate); tag != "" {
+			return fmt.Sprintf("%s - %s - %s", fields.Series, tag, item.Name)
 		}
-		return fmt.Sprintf("%s - %s", series, item.Name)
+		return fmt.Sprintf("%s - %s", fields.Series, item.Name)
+	}
+	if name := executeNameTemplate(naming.MovieTemplate, fields); name != "" {
+		return name
 	}
 	if item.ProductionYear > 0 {
 		return fmt.Sprintf("%s (%d)", item.Name, item.ProductionYear)
@@ -493,23 +767,29 @@ func buildItemFilename(item api.Item) string {
 	return item.Name
 }
 
-func buildDefaultPath(root string, item api.Item) string {
-	ext := fileExtension(item.Path)
+func buildDefaultPath(root string, item api.Item, naming namingOptions) string {
+	ext := fileExtension(item.PrimarySource().Path)
+	if naming.Extension != "" {
+		ext = naming.Extension
+	}
+	if item.Type == "Audio" {
+		return buildTrackPath(root, item, naming, ext)
+	}
+	if item.Type == "Book" {
+		return buildBookPath(root, item)
+	}
 	if item.Type == "Episode" {
 		series := item.SeriesName
 		if series == "" {
 			series = "Series"
 		}
 		seriesFolder := download.SanitizeFileName(series)
-		seasonFolder := "Season 00"
-		if item.ParentIndexNumber > 0 {
-			seasonFolder = fmt.Sprintf("Season %02d", item.ParentIndexNumber)
-		}
-		fileName := download.SanitizeFileName(buildItemFilename(item)) + ext
+		seasonFolder := download.SanitizeFileName(seasonFolderName(item.ParentIndexNumber, naming))
+		fileName := download.SanitizeFileName(buildItemFilename(item, naming)) + ext
 		return filepath.Join(root, seriesFolder, seasonFolder, fileName)
 	}
 
-	movieName := download.SanitizeFileName(buildItemFilename(item))
+	movieName := download.SanitizeFileName(buildItemFilename(item, naming))
 	fileName := movieName + ext
 	return filepath.Join(root, movieName, fileName)
 }
@@ -525,6 +805,69 @@ func fileExtension(path string) string {
 	return ext
 }
 
+func alreadyDownloaded(storeDB *store.Store, itemID string) (bool, error) {
+	done, err := storeDB.LatestDownload(itemID, "done")
+	if err != nil {
+		return false, err
+	}
+	return done != nil && downloadComplete(done), nil
+}
+
+// downloadedWithProfile reports whether itemID has a complete download made
+// with the given transcode profile ("" for the original file).
+func downloadedWithProfile(storeDB *store.Store, itemID, profile string) (bool, error) {
+	done, err := storeDB.LatestDownload(itemID, "done")
+	if err != nil {
+		return false, err
+	}
+	return done != nil && done.Profile.String == profile && downloadComplete(done), nil
+}
+
+// itemDownloaded is alreadyDownloaded for a whole item: a stacked movie
+// only counts as downloaded once all of its parts are.
+func itemDownloaded(storeDB *store.Store, item api.Item) (bool, error) {
+	if item.PartCount <= 1 {
+		return alreadyDownloaded(storeDB, item.Id)
+	}
+	files, err := storeDB.ItemFiles(item.Id)
+	if err != nil {
+		return false, err
+	}
+	if len(files) < item.PartCount {
+		return false, nil
+	}
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.Size() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// setDownloadSource records the server file a download is made from.
+func setDownloadSource(record *store.Download, item api.Item) {
+	if len(item.MediaSources) == 0 {
+		return
+	}
+	source := item.PrimarySource()
+	record.SourceID = sqlNullString(source.Id)
+	if source.Size > 0 {
+		record.SourceSize = sql.NullInt64{Int64: source.Size, Valid: true}
+	}
+	record.SourceETag = sqlNullString(source.ETag)
+}
+
+func downloadComplete(d *store.Download) bool {
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return false
+	}
+	if d.BytesTotal.Valid && d.BytesTotal.Int64 > 0 {
+		return info.Size() == d.BytesTotal.Int64
+	}
+	return info.Size() > 0
+}
+
 func existingFileSize(path string) int64 {
 	info, err := os.Stat(path)
 	if err != nil {
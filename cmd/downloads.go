@@ -7,6 +7,7 @@ import (
 
 	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
 	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/versions"
 	"github.com/spf13/cobra"
 )
 
@@ -124,11 +125,27 @@ var downloadsResumeCmd = &cobra.Command{
 			if err != nil {
 				return exitError(4, err)
 			}
+			profile, err := profileFromRecord(rec.Profile.String)
+			if err != nil {
+				return err
+			}
+			// Resume the exact source the download started from; the stored
+			// version name is only a fallback for rows without one.
+			var version *versions.Selector
+			if rec.SourceID.Valid {
+				version = &versions.Selector{ID: rec.SourceID.String}
+			} else if rec.Version.Valid {
+				version = &versions.Selector{Name: rec.Version.String}
+			}
 			opts := downloadOptions{
 				Rate:         resolveRate(cfg.DefaultRate),
 				Output:       filepath.Dir(rec.Path),
 				OverridePath: rec.Path,
 				Series:       rec.SeriesID.String,
+				Profile:      profile,
+				Version:      version,
+				Parent:       rec.ParentID.String,
+				Part:         int(rec.Part.Int64),
 			}
 			if err := downloadItem(client, storeDB, *item, filepath.Dir(rec.Path), limiter, opts); err != nil {
 				return err
@@ -140,7 +157,7 @@ var downloadsResumeCmd = &cobra.Command{
 }
 
 func init() {
-	downloadsListCmd.Flags().StringVar(&listStatus, "status", "", "Filter by status (queued, downloading, done, failed)")
+	downloadsListCmd.Flags().StringVar(&listStatus, "status", "", "Filter by status (queued, downloading, done, failed, removed)")
 
 	downloadsCmd.AddCommand(downloadsListCmd)
 	downloadsCmd.AddCommand(downloadsShowCmd)
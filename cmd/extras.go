@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+)
+
+var downloadExtrasFlag bool
+
+var extraFolders = map[string]string{
+	"Trailer":         "trailers",
+	"BehindTheScenes": "behind the scenes",
+	"DeletedScene":    "deleted scenes",
+	"Featurette":      "featurettes",
+	"Interview":       "interviews",
+	"Scene":           "scenes",
+	"Short":           "shorts",
+	"Clip":            "extras",
+	"ThemeVideo":      "backdrops",
+}
+
+type plannedDownload struct {
+	Item api.Item
+	Path string
+}
+
+func init() {
+	downloadMovieCmd.Flags().BoolVar(&downloadExtrasFlag, "extras", false, "Also download trailers, special features and theme media")
+	downloadSeriesCmd.Flags().BoolVar(&downloadExtrasFlag, "extras", false, "Also download trailers, special features and theme media")
+}
+
+// runDownloadExtras downloads the local trailers, special features and theme
+// media of owner (a movie or series) into the standard extras subfolders of
+// the owner's folder. Each extra is tracked with owner as its parent.
+func runDownloadExtras(client *api.Client, storeDir string, owner api.Item, opts downloadOptions) error {
+	storeDB, outputDir, limiter, err := openDownloadTarget(storeDir, opts)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	folder, err := extrasFolder(client, outputDir, owner, opts.Naming)
+	if err != nil {
+		return err
+	}
+	extras, err := collectExtras(client, owner, folder)
+	if err != nil {
+		return exitError(4, err)
+	}
+	if len(extras) == 0 {
+		printInfo("No extras found for %s\n", owner.Name)
+		return nil
+	}
+
+	extraOpts := downloadOptions{
+		Rate:   opts.Rate,
+		Output: opts.Output,
+		DryRun: opts.DryRun,
+		Series: opts.Series,
+		Parent: owner.Id,
+	}
+	for _, extra := range extras {
+		extraOpts.OverridePath = extra.Path
+		if err := downloadItem(client, storeDB, extra.Item, outputDir, limiter, extraOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extrasFolder(client *api.Client, root string, owner api.Item, naming namingOptions) (string, error) {
+	if owner.Type != "Series" {
+		return filepath.Dir(buildItemPath(client, root, owner, naming)), nil
+	}
+	if naming.Layout == layoutMirror && owner.Path != "" {
+		if path, err := buildMirrorPath(client, root, owner); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(root, download.SanitizeFileName(owner.Name)), nil
+}
+
+func collectExtras(client *api.Client, owner api.Item, folder string) ([]plannedDownload, error) {
+	trailers, err := client.LocalTrailers(ctx, owner.Id)
+	if err != nil {
+		return nil, err
+	}
+	features, err := client.SpecialFeatures(ctx, owner.Id)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := client.ThemeMedia(ctx, owner.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []plannedDownload
+	used := map[string]bool{}
+	add := func(item api.Item, path string) {
+		for n := 2; used[path]; n++ {
+			ext := filepath.Ext(path)
+			path = fmt.Sprintf("%s (%d)%s", path[:len(path)-len(ext)], n, ext)
+		}
+		used[path] = true
+		out = append(out, plannedDownload{Item: item, Path: path})
+	}
+
+	for _, item := range trailers {
+		add(item, extraPath(folder, "Trailer", item))
+	}
+	for _, item := range features {
+		add(item, extraPath(folder, item.ExtraType, item))
+	}
+	for _, item := range theme.ThemeVideosResult.Items {
+		add(item, extraPath(folder, "ThemeVideo", item))
+	}
+	for i, item := range theme.ThemeSongsResult.Items {
+		ext := extraExtension(item)
+		if i == 0 {
+			add(item, filepath.Join(folder, "theme"+ext))
+			continue
+		}
+		add(item, filepath.Join(folder, "theme-music", download.SanitizeFileName(item.Name)+ext))
+	}
+	return out, nil
+}
+
+func extraPath(folder, extraType string, item api.Item) string {
+	sub, ok := extraFolders[extraType]
+	if !ok {
+		sub = "extras"
+	}
+	return filepath.Join(folder, sub, download.SanitizeFileName(item.Name)+extraExtension(item))
+}
+
+func extraExtension(item api.Item) string {
+	if filepath.Ext(item.Path) == "" && item.Type == "Audio" {
+		return ".mp3"
+	}
+	return fileExtension(item.Path)
+}
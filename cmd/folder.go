@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/spf13/cobra"
+)
+
+var downloadFolderCmd = &cobra.Command{
+	Use:   "folder",
+	Short: "Download a folder, photo album or home video library with its folder tree",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		folderID, err := resolveItemID(cmd, args, "Folder")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		folder, err := client.GetItem(ctx, folderID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		return runDownloadFolder(client, storeDir, *folder, opts)
+	},
+}
+
+func init() {
+	downloadFolderCmd.Flags().String("id", "", "Folder, photo album or library ID")
+	downloadCmd.AddCommand(downloadFolderCmd)
+}
+
+// runDownloadFolder recreates the server folder tree below root under the
+// output directory, keeping the original file names and creation times.
+func runDownloadFolder(client *api.Client, storeDir string, root api.Item, opts downloadOptions) error {
+	storeDB, outputDir, limiter, err := openDownloadTarget(storeDir, opts)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	var files []plannedDownload
+	if err := collectFolder(client, root, filepath.Join(outputDir, folderName(root)), &files); err != nil {
+		return exitError(4, err)
+	}
+	if len(files) == 0 {
+		printInfo("No photos or videos found in %s\n", root.Name)
+		return nil
+	}
+
+	for _, file := range files {
+		done, err := itemDownloaded(storeDB, file.Item)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+		fileOpts := opts
+		fileOpts.OverridePath = file.Path
+		if file.Item.Type == "Photo" {
+			fileOpts.Profile = nil
+		} else if opts.Naming.Extension != "" {
+			// Transcoded videos keep their name but get the profile's
+			// container extension.
+			fileOpts.OverridePath = strings.TrimSuffix(file.Path, filepath.Ext(file.Path)) + opts.Naming.Extension
+		}
+		if err := downloadItem(client, storeDB, file.Item, outputDir, limiter, fileOpts); err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			keepTimestamp(file.Item, fileOpts.OverridePath)
+		}
+	}
+	return nil
+}
+
+func collectFolder(client *api.Client, folder api.Item, dir string, out *[]plannedDownload) error {
+	children, err := client.FolderItems(ctx, folder.Id)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		switch child.Type {
+		case "Folder", "CollectionFolder", "PhotoAlbum":
+			if err := collectFolder(client, child, filepath.Join(dir, folderName(child)), out); err != nil {
+				return err
+			}
+		case "Photo", "Video":
+			*out = append(*out, plannedDownload{Item: child, Path: filepath.Join(dir, folderFileName(child))})
+		default:
+			printInfo("Skipping %s (%s)\n", child.Name, child.Type)
+		}
+	}
+	return nil
+}
+
+func folderName(item api.Item) string {
+	if name := download.ServerBaseName(item.Path); name != "" && item.Type != "CollectionFolder" {
+		return download.SanitizePathSegment(name)
+	}
+	return download.SanitizePathSegment(item.Name)
+}
+
+func folderFileName(item api.Item) string {
+	if name := download.ServerBaseName(item.PrimarySource().Path); name != "" {
+		return download.SanitizePathSegment(name)
+	}
+	ext := fileExtension(item.Path)
+	if item.Type == "Photo" && filepath.Ext(item.Path) == "" {
+		ext = ".jpg"
+	}
+	return download.SanitizePathSegment(item.Name + ext)
+}
+
+func keepTimestamp(item api.Item, path string) {
+	created, err := time.Parse(time.RFC3339Nano, item.DateCreated)
+	if err != nil {
+		return
+	}
+	if err := os.Chtimes(path, created, created); err != nil && !os.IsNotExist(err) {
+		printError("keeping timestamp of %s failed: %v\n", path, err)
+	}
+}
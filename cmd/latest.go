@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultLatestTypes = "movie,episode"
+	defaultLatestLimit = 100
+)
+
+var (
+	latestType            string
+	latestSince           string
+	latestLibrary         string
+	latestLimit           int
+	downloadLatestType    string
+	downloadLatestSince   string
+	downloadLatestLibrary string
+	downloadLatestLimit   int
+)
+
+// latestDownloadable are the item types `download latest` can save
+// directly; other types (series, albums) are skipped.
+var latestDownloadable = map[string]bool{
+	"Movie":   true,
+	"Episode": true,
+	"Audio":   true,
+	"Book":    true,
+	"Video":   true,
+}
+
+var latestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "List recently added items",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseSince(latestSince)
+		if err != nil {
+			return err
+		}
+		client, _, _, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		items, err := fetchLatest(client, latestType, latestLibrary, latestLimit, since)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			outputJSON(items)
+			return nil
+		}
+		if plainOutput {
+			for _, item := range items {
+				fmt.Printf("%s\t%s\t%s\n", item.Id, item.Name, item.Type)
+			}
+			return nil
+		}
+		for _, item := range items {
+			fmt.Printf("%s  %s (%s)\n", item.Id, item.Name, item.Type)
+		}
+		return nil
+	},
+}
+
+var downloadLatestCmd = &cobra.Command{
+	Use:   "latest",
+	Short: "Download everything added recently",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseSince(downloadLatestSince)
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+		items, err := fetchLatest(client, downloadLatestType, downloadLatestLibrary, downloadLatestLimit, since)
+		if err != nil {
+			return err
+		}
+
+		var wanted []api.Item
+		for _, item := range items {
+			if !latestDownloadable[item.Type] {
+				printInfo("Skipping %s (%s)\n", item.Name, item.Type)
+				continue
+			}
+			wanted = append(wanted, item)
+		}
+		if len(wanted) == 0 {
+			printInfo("Nothing new\n")
+			return nil
+		}
+		printInfo("Found %d new items\n", len(wanted))
+		// Scheduled runs overlap their --since windows.
+		opts.SkipDone = true
+		return runDownloadItems(client, storeDir, wanted, opts)
+	},
+}
+
+func init() {
+	latestCmd.Flags().StringVar(&latestType, "type", defaultLatestTypes, "Item types: movie, series, episode, album, track, book, ...")
+	latestCmd.Flags().StringVar(&latestSince, "since", "", "Only items added within this time (e.g. 24h, 7d)")
+	latestCmd.Flags().StringVar(&latestLibrary, "library", "", "Only items of this library (see `libraries list`)")
+	latestCmd.Flags().IntVar(&latestLimit, "limit", 0, "Max items (default 100 without --since, all with it)")
+	rootCmd.AddCommand(latestCmd)
+
+	downloadLatestCmd.Flags().StringVar(&downloadLatestType, "type", defaultLatestTypes, "Item types: movie, episode, track, book")
+	downloadLatestCmd.Flags().StringVar(&downloadLatestSince, "since", "24h", "Only items added within this time (e.g. 24h, 7d)")
+	downloadLatestCmd.Flags().StringVar(&downloadLatestLibrary, "library", "", "Only items of this library (see `libraries list`)")
+	downloadLatestCmd.Flags().IntVar(&downloadLatestLimit, "limit", 0, "Max items (default 100 without --since, all with it)")
+	downloadCmd.AddCommand(downloadLatestCmd)
+}
+
+// parseSince turns a --since value into the earliest accepted date added,
+// or the zero time when value is empty.
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	age, err := download.ParseAge(value)
+	if err != nil {
+		return time.Time{}, exitError(2, fmt.Errorf("--since: %w", err))
+	}
+	return time.Now().Add(-age), nil
+}
+
+// fetchLatest returns the newest items of the given types. Without since it
+// asks the latest-items endpoint for at most limit items (default 100);
+// with since it pages through everything added after since, so a large
+// import is not cut off, and limit (if set) caps the result.
+func fetchLatest(client *api.Client, types, libraryID string, limit int, since time.Time) ([]api.Item, error) {
+	if since.IsZero() {
+		if limit <= 0 {
+			limit = defaultLatestLimit
+		}
+		items, err := client.LatestItems(ctx, libraryID, parseSearchTypes(types), limit)
+		if err != nil {
+			return nil, exitError(4, err)
+		}
+		return items, nil
+	}
+	items, err := client.QueryAllItems(ctx, api.ItemQuery{
+		ParentID:       libraryID,
+		Types:          parseSearchTypes(types),
+		Recursive:      true,
+		MinDateCreated: since,
+		SortBy:         []string{"DateCreated"},
+		SortOrder:      "Descending",
+		UserData:       true,
+	})
+	if err != nil {
+		return nil, exitError(4, err)
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	libraryTypes     string
+	libraryGenres    string
+	libraryYears     string
+	libraryMinRating float64
+	libraryUnplayed  bool
+)
+
+var librariesCmd = &cobra.Command{
+	Use:   "libraries",
+	Short: "Browse the server's libraries",
+}
+
+var librariesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List libraries (views) with their IDs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, _, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		views, err := client.UserViews(ctx)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if jsonOutput {
+			outputJSON(views)
+			return nil
+		}
+		for _, v := range views {
+			fmt.Printf("%s\t%s\t%s\n", v.Id, v.Name, v.CollectionType)
+		}
+		return nil
+	},
+}
+
+var downloadLibraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "Download an entire library or a filtered subset of it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		libraryID, err := resolveItemID(cmd, args, "Library")
+		if err != nil {
+			return err
+		}
+		types, err := parseLibraryTypes(libraryTypes)
+		if err != nil {
+			return exitError(2, err)
+		}
+		years, err := parseYears(libraryYears)
+		if err != nil {
+			return exitError(2, err)
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		query := api.ItemQuery{
+			ParentID:  libraryID,
+			Types:     types,
+			Recursive: true,
+			Genres:    splitList(libraryGenres),
+			Years:     years,
+			MinRating: libraryMinRating,
+			SortBy:    []string{"SeriesSortName", "ParentIndexNumber", "IndexNumber", "SortName"},
+		}
+		if libraryUnplayed {
+			played := false
+			query.IsPlayed = &played
+		}
+		items, err := client.QueryAllItems(ctx, query)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if len(items) == 0 {
+			printInfo("No matching items found\n")
+			return nil
+		}
+		printInfo("Found %d items\n", len(items))
+
+		opts.QueueFirst = true
+		return runDownloadItems(client, storeDir, items, opts)
+	},
+}
+
+func init() {
+	downloadLibraryCmd.Flags().String("id", "", "Library (view) ID, see `libraries list`")
+	downloadLibraryCmd.Flags().StringVar(&libraryTypes, "type", "movie,episode,track", "Item types to download (movie, episode, track)")
+	downloadLibraryCmd.Flags().StringVar(&libraryGenres, "genre", "", "Only items with one of these genres (comma-separated)")
+	downloadLibraryCmd.Flags().StringVar(&libraryYears, "year", "", "Only items from these years, e.g. 2015 or 2010-2020")
+	downloadLibraryCmd.Flags().Float64Var(&libraryMinRating, "min-rating", 0, "Only items with at least this community rating")
+	downloadLibraryCmd.Flags().BoolVar(&libraryUnplayed, "unplayed", false, "Only items not yet played")
+	downloadCmd.AddCommand(downloadLibraryCmd)
+
+	librariesCmd.AddCommand(librariesListCmd)
+	rootCmd.AddCommand(librariesCmd)
+}
+
+func parseLibraryTypes(value string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, t := range splitList(value) {
+		var itemType string
+		switch strings.ToLower(t) {
+		case "movie", "movies":
+			itemType = "Movie"
+		case "episode", "episodes", "series", "show":
+			itemType = "Episode"
+		case "track", "tracks", "audio", "music":
+			itemType = "Audio"
+		default:
+			return nil, fmt.Errorf("unknown type %q (use movie, episode or track)", t)
+		}
+		if !seen[itemType] {
+			seen[itemType] = true
+			out = append(out, itemType)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no item types given")
+	}
+	return out, nil
+}
+
+func parseYears(value string) ([]int, error) {
+	var out []int
+	for _, part := range splitList(value) {
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid year %q", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil {
+				return nil, fmt.Errorf("invalid year range %q", part)
+			}
+		}
+		if end < start || end-start > 500 {
+			return nil, fmt.Errorf("invalid year range %q", part)
+		}
+		for y := start; y <= end; y++ {
+			out = append(out, y)
+		}
+	}
+	return out, nil
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/music"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downloadLyrics bool
+	noCoverArt     bool
+)
+
+var downloadAlbumCmd = &cobra.Command{
+	Use:   "album",
+	Short: "Download a music album",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		albumID, err := resolveItemID(cmd, args, "Album")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		tracks, err := client.AlbumTracks(ctx, albumID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if len(tracks) == 0 {
+			printInfo("No tracks found\n")
+			return nil
+		}
+		opts.Naming.MultiDiscAlbums = multiDiscAlbums(tracks)
+		return runDownloadItems(client, storeDir, tracks, opts)
+	},
+}
+
+var downloadArtistCmd = &cobra.Command{
+	Use:   "artist",
+	Short: "Download all albums of a music artist",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artistID, err := resolveItemID(cmd, args, "Artist")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		albums, err := client.ArtistAlbums(ctx, artistID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		var tracks []api.Item
+		for _, album := range albums {
+			albumTracks, err := client.AlbumTracks(ctx, album.Id)
+			if err != nil {
+				return exitError(4, err)
+			}
+			tracks = append(tracks, albumTracks...)
+		}
+		if len(tracks) == 0 {
+			printInfo("No tracks found\n")
+			return nil
+		}
+		opts.Naming.MultiDiscAlbums = multiDiscAlbums(tracks)
+		return runDownloadItems(client, storeDir, tracks, opts)
+	},
+}
+
+var downloadTrackCmd = &cobra.Command{
+	Use:   "track",
+	Short: "Download a single music track",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trackID, err := resolveItemID(cmd, args, "Track")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		item, err := client.GetItem(ctx, trackID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		return runDownloadItems(client, storeDir, []api.Item{*item}, opts)
+	},
+}
+
+func init() {
+	downloadCmd.PersistentFlags().BoolVar(&downloadLyrics, "lyrics", false, "Save synced lyrics as .lrc next to music tracks")
+	downloadCmd.PersistentFlags().BoolVar(&noCoverArt, "no-cover", false, "Do not save album cover art (folder.jpg)")
+
+	downloadAlbumCmd.Flags().String("id", "", "Album item ID")
+	downloadArtistCmd.Flags().String("id", "", "Artist item ID")
+	downloadTrackCmd.Flags().String("id", "", "Track item ID")
+
+	downloadCmd.AddCommand(downloadAlbumCmd)
+	downloadCmd.AddCommand(downloadArtistCmd)
+	downloadCmd.AddCommand(downloadTrackCmd)
+}
+
+func multiDiscAlbums(tracks []api.Item) map[string]bool {
+	discs := map[string]map[int]bool{}
+	for _, t := range tracks {
+		if discs[t.AlbumId] == nil {
+			discs[t.AlbumId] = map[int]bool{}
+		}
+		discs[t.AlbumId][t.ParentIndexNumber] = true
+	}
+	out := map[string]bool{}
+	for album, set := range discs {
+		out[album] = len(set) > 1
+	}
+	return out
+}
+
+// albumDiscs returns known extended by the disc layout of every album that
+// has tracks among items, so a single track gets the same disc prefix as it
+// would in a full album download.
+func albumDiscs(client *api.Client, items []api.Item, known map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(known))
+	for album, multi := range known {
+		out[album] = multi
+	}
+	for _, item := range items {
+		if item.Type != "Audio" || item.AlbumId == "" {
+			continue
+		}
+		if _, ok := out[item.AlbumId]; ok {
+			continue
+		}
+		tracks, err := client.AlbumTracks(ctx, item.AlbumId)
+		if err != nil {
+			printError("reading album %s failed: %v\n", item.Album, err)
+			out[item.AlbumId] = item.ParentIndexNumber > 1
+			continue
+		}
+		out[item.AlbumId] = multiDiscAlbums(tracks)[item.AlbumId]
+	}
+	return out
+}
+
+// buildTrackPath lays tracks out as "<Artist>/<Album (Year)>/<NN - Title>.ext".
+func buildTrackPath(root string, item api.Item, naming namingOptions, ext string) string {
+	multiDisc := item.ParentIndexNumber > 1
+	if naming.MultiDiscAlbums != nil {
+		multiDisc = naming.MultiDiscAlbums[item.AlbumId] || multiDisc
+	}
+	if filepath.Ext(item.Path) == "" && naming.Extension == "" {
+		ext = ".mp3"
+	}
+	artistFolder := download.SanitizePathSegment(music.ArtistName(item))
+	albumFolder := download.SanitizePathSegment(music.AlbumFolderName(item.Album, item.ProductionYear))
+	fileName := download.SanitizePathSegment(music.TrackFileName(item.ParentIndexNumber, item.IndexNumber, multiDisc, item.Name) + ext)
+	return filepath.Join(root, artistFolder, albumFolder, fileName)
+}
+
+// afterTrackDownload saves album cover art and synced lyrics for a finished
+// track. Failures are only reported as warnings.
+func afterTrackDownload(client *api.Client, item api.Item, path string, opts downloadOptions) {
+	if opts.CoverArt && item.AlbumId != "" {
+		cover := filepath.Join(filepath.Dir(path), "folder.jpg")
+		if _, err := os.Stat(cover); os.IsNotExist(err) {
+			if err := saveImage(client, item.AlbumId, "Primary", cover); err != nil {
+				printError("cover art for %s failed: %v\n", item.Album, err)
+			}
+		}
+	}
+	if opts.Lyrics {
+		if err := saveLyrics(client, item, path); err != nil {
+			printError("lyrics for %s failed: %v\n", item.Name, err)
+		}
+	}
+}
+
+func saveImage(client *api.Client, itemID, imageType, path string) error {
+	resp, err := client.OpenImage(ctx, itemID, imageType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return saveResponse(resp.Body, path)
+}
+
+func saveLyrics(client *api.Client, item api.Item, path string) error {
+	lyrics, err := client.Lyrics(ctx, item.Id)
+	if err != nil {
+		return err
+	}
+	if !music.IsSynced(lyrics.Lyrics) {
+		return nil
+	}
+	lrcPath := path[:len(path)-len(filepath.Ext(path))] + ".lrc"
+	if err := os.WriteFile(lrcPath, []byte(music.FormatLRC(lyrics.Lyrics)), 0600); err != nil {
+		return fmt.Errorf("writing lyrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+)
+
+const (
+	defaultSpecialsFolder = "Season 00"
+
+	layoutPlex   = "plex"
+	layoutMirror = "mirror"
+)
+
+var (
+	layoutMode      string
+	specialsFolder  string
+	episodeTemplate string
+	movieTemplate   string
+	absoluteNumbers bool
+)
+
+type namingOptions struct {
+	Layout         string
+	SpecialsFolder string
+	// Extension overrides the file extension, e.g. for transcoded output.
+	Extension       string
+	EpisodeTemplate *template.Template
+	MovieTemplate   *template.Template
+	// MultiDiscAlbums marks album IDs whose tracks span several discs.
+	MultiDiscAlbums map[string]bool
+	// Absolute maps episode item IDs to their absolute episode number.
+	// When non-nil, episodes are named by absolute number instead of SxxEyy.
+	Absolute map[string]int
+}
+
+// nameFields is the data passed to --episode-template and --movie-template.
+type nameFields struct {
+	Type              string
+	Name              string
+	Series            string
+	Year              int
+	Season            int
+	Episode           int
+	EpisodeEnd        int
+	Absolute          int
+	AirDate           string
+	AirsBeforeSeason  int
+	AirsBeforeEpisode int
+	Version           string
+	Resolution        string
+	HDR               string
+}
+
+func resolveNaming(cfg *config.Config) (namingOptions, error) {
+	opts := namingOptions{Layout: layoutPlex, SpecialsFolder: defaultSpecialsFolder}
+	if cfg.Layout != "" {
+		opts.Layout = cfg.Layout
+	}
+	if layoutMode != "" {
+		opts.Layout = layoutMode
+	}
+	opts.Layout = strings.ToLower(strings.TrimSpace(opts.Layout))
+	if opts.Layout != layoutPlex && opts.Layout != layoutMirror {
+		return opts, exitError(2, fmt.Errorf("unknown layout %q (use plex or mirror)", opts.Layout))
+	}
+	if cfg.SpecialsFolder != "" {
+		opts.SpecialsFolder = cfg.SpecialsFolder
+	}
+	if specialsFolder != "" {
+		opts.SpecialsFolder = specialsFolder
+	}
+
+	episodeTpl := cfg.EpisodeTemplate
+	if episodeTemplate != "" {
+		episodeTpl = episodeTemplate
+	}
+	movieTpl := cfg.MovieTemplate
+	if movieTemplate != "" {
+		movieTpl = movieTemplate
+	}
+
+	var err error
+	if opts.EpisodeTemplate, err = parseNameTemplate("episode", episodeTpl); err != nil {
+		return opts, exitError(2, err)
+	}
+	if opts.MovieTemplate, err = parseNameTemplate("movie", movieTpl); err != nil {
+		return opts, exitError(2, err)
+	}
+	return opts, nil
+}
+
+func parseNameTemplate(name, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	if err := tpl.Execute(&bytes.Buffer{}, nameFields{}); err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tpl, nil
+}
+
+func itemNameFields(item api.Item, naming namingOptions) nameFields {
+	series := item.SeriesName
+	if series == "" {
+		series = "Series"
+	}
+	fields := nameFields{
+		Type:              item.Type,
+		Name:              item.Name,
+		Series:            series,
+		Year:              item.ProductionYear,
+		Season:            item.ParentIndexNumber,
+		Episode:           item.IndexNumber,
+		AirsBeforeSeason:  item.AirsBeforeSeasonNumber,
+		AirsBeforeEpisode: item.AirsBeforeEpisodeNumber,
+	}
+	if item.IndexNumberEnd > item.IndexNumber {
+		fields.EpisodeEnd = item.IndexNumberEnd
+	}
+	if len(item.PremiereDate) >= 10 {
+		fields.AirDate = item.PremiereDate[:10]
+	}
+	if naming.Absolute != nil {
+		fields.Absolute = naming.Absolute[item.Id]
+	}
+	if len(item.MediaSources) > 0 {
+		source := item.PrimarySource()
+		fields.Version = versionName(item)
+		fields.Resolution = source.Resolution()
+		fields.HDR = source.HDRType()
+	}
+	return fields
+}
+
+func executeNameTemplate(tpl *template.Template, fields nameFields) string {
+	if tpl == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, fields); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// keepsGeneratedName reports whether a naming option shapes the file name
+// of item, in which case the server's file name must not replace it.
+func keepsGeneratedName(item api.Item, naming namingOptions) bool {
+	switch item.Type {
+	case "Episode":
+		if naming.EpisodeTemplate != nil || item.IndexNumberEnd > item.IndexNumber {
+			return true
+		}
+		_, ok := naming.Absolute[item.Id]
+		return ok
+	case "Audio", "Book":
+		return true
+	default:
+		return naming.MovieTemplate != nil
+	}
+}
+
+func buildItemPath(client *api.Client, root string, item api.Item, naming namingOptions) string {
+	if naming.Layout == layoutMirror {
+		path, err := buildMirrorPath(client, root, item)
+		if err == nil {
+			if naming.Extension != "" {
+				path = strings.TrimSuffix(path, filepath.Ext(path)) + naming.Extension
+			}
+			return path
+		}
+		if err != libraryLocationsErr {
+			printError("mirror layout unavailable for %s, using default layout: %v\n", item.Name, err)
+		}
+	}
+	return buildDefaultPath(root, item, naming)
+}
+
+// libraryLocations caches the physical library folders, longest first, so
+// the nearest library root wins when libraries are nested. If they cannot
+// be read, libraryLocationsErr is kept so the request is made only once.
+var (
+	libraryLocations    []string
+	libraryLocationsErr error
+)
+
+// buildMirrorPath recreates the item's server-side path below root, relative
+// to the physical library folder that contains it.
+func buildMirrorPath(client *api.Client, root string, item api.Item) (string, error) {
+	if item.Path == "" {
+		return "", fmt.Errorf("server did not report a file path")
+	}
+	if libraryLocationsErr != nil {
+		return "", libraryLocationsErr
+	}
+	if libraryLocations == nil {
+		folders, err := client.VirtualFolders(ctx)
+		if err != nil {
+			libraryLocations = []string{}
+			libraryLocationsErr = fmt.Errorf("reading library folders: %w", err)
+			printError("mirror layout unavailable, using default layout: %v\n", libraryLocationsErr)
+			return "", libraryLocationsErr
+		}
+		locations := []string{}
+		for _, folder := range folders {
+			locations = append(locations, folder.Locations...)
+		}
+		sort.Slice(locations, func(i, j int) bool { return len(locations[i]) > len(locations[j]) })
+		libraryLocations = locations
+	}
+	for _, location := range libraryLocations {
+		if path, err := download.MirrorPath(root, location, item.Path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not inside a library folder", item.Path)
+}
+
+func seasonFolderName(season int, naming namingOptions) string {
+	if season > 0 {
+		return fmt.Sprintf("Season %02d", season)
+	}
+	if naming.SpecialsFolder != "" {
+		return naming.SpecialsFolder
+	}
+	return defaultSpecialsFolder
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nextUpPerSeries int
+	nextUpSeries    string
+)
+
+var downloadNextUpCmd = &cobra.Command{
+	Use:   "next-up",
+	Short: "Download the next unwatched episodes of the series you are watching",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if nextUpPerSeries < 1 {
+			return exitError(2, fmt.Errorf("--per-series must be at least 1"))
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		nextUp, err := client.NextUp(ctx, nextUpSeries, 0)
+		if err != nil {
+			return exitError(4, err)
+		}
+		var items []api.Item
+		for _, next := range nextUp {
+			episodes, err := nextEpisodes(client, next, nextUpPerSeries)
+			if err != nil {
+				return exitError(4, err)
+			}
+			items = append(items, episodes...)
+		}
+		if len(items) == 0 {
+			printInfo("Nothing up next\n")
+			return nil
+		}
+		// Run before every trip; episodes from the last run are kept.
+		opts.SkipDone = true
+		return runDownloadItems(client, storeDir, items, opts)
+	},
+}
+
+var downloadResumeListCmd = &cobra.Command{
+	Use:   "resume-list",
+	Short: "Download the movies and episodes you are in the middle of watching",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		resume, err := client.ResumeItems(ctx)
+		if err != nil {
+			return exitError(4, err)
+		}
+		var items []api.Item
+		for _, item := range resume {
+			if item.Type != "Movie" && item.Type != "Episode" {
+				printInfo("Skipping %s (%s)\n", item.Name, item.Type)
+				continue
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			printInfo("Nothing to continue watching\n")
+			return nil
+		}
+		opts.SkipDone = true
+		return runDownloadItems(client, storeDir, items, opts)
+	},
+}
+
+func init() {
+	downloadNextUpCmd.Flags().IntVar(&nextUpPerSeries, "per-series", 1, "Number of upcoming episodes to download per series")
+	downloadNextUpCmd.Flags().StringVar(&nextUpSeries, "series", "", "Only this series ID")
+	downloadCmd.AddCommand(downloadNextUpCmd)
+	downloadCmd.AddCommand(downloadResumeListCmd)
+}
+
+// nextEpisodes returns next and the episodes following it in the series, up
+// to count episodes in total.
+func nextEpisodes(client *api.Client, next api.Item, count int) ([]api.Item, error) {
+	if count == 1 || next.SeriesId == "" {
+		return []api.Item{next}, nil
+	}
+	episodes, err := client.SeriesEpisodes(ctx, next.SeriesId)
+	if err != nil {
+		return nil, err
+	}
+	episodes = availableEpisodes(episodes)
+	for i, ep := range episodes {
+		if ep.Id != next.Id {
+			continue
+		}
+		end := i + count
+		if end > len(episodes) {
+			end = len(episodes)
+		}
+		return episodes[i:end], nil
+	}
+	return []api.Item{next}, nil
+}
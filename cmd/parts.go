@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"golang.org/x/time/rate"
+)
+
+// downloadMovieParts downloads every part of a stacked movie as its own
+// tracked download in the movie's folder, named "<movie> - pt1.ext", ...
+func downloadMovieParts(client *api.Client, storeDB *store.Store, movie api.Item, outputDir string, limiter *rate.Limiter, opts downloadOptions) error {
+	additional, err := client.AdditionalParts(ctx, movie.Id)
+	if err != nil {
+		return exitError(4, err)
+	}
+	movie, err = applyVersion(movie, opts.Version)
+	if err != nil {
+		return exitError(2, err)
+	}
+	parts := append([]api.Item{movie}, additional...)
+
+	basePath := buildItemPath(client, outputDir, movie, opts.Naming)
+	profile := ""
+	if opts.Profile != nil {
+		profile = opts.Profile.String()
+	}
+	complete := 0
+	for i, part := range parts {
+		// A finished part is not requested again; resuming it at its full
+		// size would only be answered with 416. Parts made with another
+		// profile are downloaded again.
+		done, err := downloadedWithProfile(storeDB, part.Id, profile)
+		if err != nil {
+			return err
+		}
+		if done {
+			complete++
+			continue
+		}
+		partOpts := opts
+		partOpts.Parent = movie.Id
+		partOpts.Part = i + 1
+		partOpts.Version = nil
+		if opts.Naming.Layout != layoutMirror {
+			partOpts.OverridePath = partPath(basePath, i+1, part, opts.Naming)
+		}
+		if part.Type == "" {
+			part.Type = movie.Type
+		}
+		part.Name = fmt.Sprintf("%s - pt%d", movie.Name, i+1)
+		part.ProductionYear = movie.ProductionYear
+		if err := downloadItem(client, storeDB, part, outputDir, limiter, partOpts); err != nil {
+			return err
+		}
+		done, err = downloadedWithProfile(storeDB, part.Id, profile)
+		if err != nil {
+			return err
+		}
+		if done {
+			complete++
+		}
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if complete == len(parts) {
+		printInfo("Completed %s (%d parts)\n", movie.Name, len(parts))
+	} else {
+		printInfo("%s: %d of %d parts downloaded\n", movie.Name, complete, len(parts))
+	}
+	return nil
+}
+
+func partPath(basePath string, part int, item api.Item, naming namingOptions) string {
+	ext := fileExtension(item.PrimarySource().Path)
+	if naming.Extension != "" {
+		ext = naming.Extension
+	}
+	base := strings.TrimSuffix(basePath, filepath.Ext(basePath))
+	return fmt.Sprintf("%s - pt%d%s", base, part, ext)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/music"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/playlist"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+const ticksPerSecond = 10000000
+
+var downloadPlaylistCmd = &cobra.Command{
+	Use:   "playlist",
+	Short: "Download a playlist and write an .m3u8 file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		playlistID, err := resolveItemID(cmd, args, "Playlist")
+		if err != nil {
+			return err
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		list, err := client.GetItem(ctx, playlistID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		entries, err := client.PlaylistItems(ctx, playlistID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if len(entries) == 0 {
+			printInfo("Playlist is empty\n")
+		}
+
+		// Re-running a playlist only fetches the entries added since.
+		opts.SkipDone = true
+		if err := runDownloadItems(client, storeDir, entries, opts); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return nil
+		}
+		return writePlaylistFile(storeDir, *list, entries, opts)
+	},
+}
+
+func init() {
+	downloadPlaylistCmd.Flags().String("id", "", "Playlist item ID")
+	downloadCmd.AddCommand(downloadPlaylistCmd)
+}
+
+// writePlaylistFile (re)generates "<output>/<playlist>.m3u8" from the
+// downloaded files of entries, in playlist order.
+func writePlaylistFile(storeDir string, list api.Item, entries []api.Item, opts downloadOptions) error {
+	storeDB, err := store.Open(storeDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	outputDir := resolveOutputDir(storeDir, opts)
+	m3uPath := filepath.Join(outputDir, download.SanitizeFileName(list.Name)+".m3u8")
+
+	var lines []playlist.Entry
+	for _, entry := range entries {
+		files, err := storeDB.ItemFiles(entry.Id)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			printError("%s is not downloaded, leaving it out of the playlist\n", entry.Name)
+			continue
+		}
+		for i, file := range files {
+			line := playlist.Entry{Path: file, Title: playlistTitle(entry)}
+			if len(files) == 1 {
+				line.Duration = int(entry.RunTimeTicks / ticksPerSecond)
+			} else {
+				line.Title = fmt.Sprintf("%s - pt%d", line.Title, i+1)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	previous := map[string]bool{}
+	if f, err := os.Open(m3uPath); err == nil {
+		paths, _ := playlist.ReadM3U(f)
+		f.Close()
+		for _, p := range paths {
+			previous[p] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := playlist.WriteM3U8(&buf, outputDir, lines); err != nil {
+		return err
+	}
+	current, _ := playlist.ReadM3U(bytes.NewReader(buf.Bytes()))
+	added := 0
+	for _, p := range current {
+		if previous[p] {
+			delete(previous, p)
+			continue
+		}
+		added++
+	}
+
+	if err := saveResponse(&buf, m3uPath); err != nil {
+		return err
+	}
+	printInfo("Wrote %s (%d entries, %d added, %d removed)\n", m3uPath, len(lines), added, len(previous))
+	return nil
+}
+
+func playlistTitle(item api.Item) string {
+	switch item.Type {
+	case "Audio":
+		return fmt.Sprintf("%s - %s", music.ArtistName(item), item.Name)
+	case "Episode":
+		return fmt.Sprintf("%s - %s", item.SeriesName, item.Name)
+	}
+	return item.Name
+}
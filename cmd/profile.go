@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/subtitles"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/transcode"
+)
+
+var (
+	profileName      string
+	profileContainer string
+	profileVideo     string
+	profileAudio     string
+	profileBitrate   string
+	profileMaxRes    string
+	profileAudioLang string
+)
+
+func init() {
+	downloadCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Transcode with a device profile ("+strings.Join(transcode.PresetNames(), ", ")+")")
+	downloadCmd.PersistentFlags().StringVar(&profileContainer, "container", "", "Transcode output container (e.g. mp4, mkv)")
+	downloadCmd.PersistentFlags().StringVar(&profileVideo, "video-codec", "", "Transcode video codec (e.g. h264, hevc)")
+	downloadCmd.PersistentFlags().StringVar(&profileAudio, "audio-codec", "", "Transcode audio codec (e.g. aac)")
+	downloadCmd.PersistentFlags().StringVar(&profileBitrate, "max-bitrate", "", "Transcode max video bitrate (e.g. 4M)")
+	downloadCmd.PersistentFlags().StringVar(&profileMaxRes, "max-resolution", "", "Transcode max resolution (e.g. 720p, 1280x720)")
+	downloadCmd.PersistentFlags().StringVar(&profileAudioLang, "audio-lang", "", "Preferred audio language when transcoding (e.g. en)")
+}
+
+// resolveProfile builds the transcode profile from --profile and the custom
+// flags. It returns nil when the original file should be downloaded.
+func resolveProfile() (*transcode.Profile, error) {
+	custom := profileContainer != "" || profileVideo != "" || profileAudio != "" ||
+		profileBitrate != "" || profileMaxRes != "" || profileAudioLang != ""
+	if profileName == "" && !custom {
+		return nil, nil
+	}
+
+	p := transcode.Profile{Container: "mp4", VideoCodec: "h264", AudioCodec: "aac"}
+	if profileName != "" {
+		preset, err := transcode.Lookup(profileName)
+		if err != nil {
+			return nil, exitError(2, err)
+		}
+		p = preset
+	}
+	if profileContainer != "" {
+		p.Container = strings.ToLower(profileContainer)
+	}
+	if profileVideo != "" {
+		p.VideoCodec = strings.ToLower(profileVideo)
+	}
+	if profileAudio != "" {
+		p.AudioCodec = strings.ToLower(profileAudio)
+	}
+	if profileBitrate != "" {
+		bitrate, err := transcode.ParseBitrate(profileBitrate)
+		if err != nil {
+			return nil, exitError(2, err)
+		}
+		p.MaxBitrate = bitrate
+	}
+	if profileMaxRes != "" {
+		w, h, err := transcode.ParseResolution(profileMaxRes)
+		if err != nil {
+			return nil, exitError(2, err)
+		}
+		p.MaxWidth, p.MaxHeight = w, h
+	}
+	if profileAudioLang != "" {
+		p.AudioLanguage = strings.ToLower(profileAudioLang)
+	}
+	return &p, nil
+}
+
+func openItemStream(client *api.Client, item api.Item, offset int64, opts downloadOptions) (*http.Response, error) {
+	if opts.Profile == nil {
+		if source := item.PrimarySource(); len(item.MediaSources) > 1 && source.Id != "" && source.Id != item.Id {
+			return client.OpenMediaSource(ctx, item.Id, source.Id, offset)
+		}
+		return client.OpenDownload(ctx, item.Id, offset)
+	}
+	return client.OpenTranscode(ctx, item.Id, transcodeRequest(item, *opts.Profile))
+}
+
+func transcodeRequest(item api.Item, p transcode.Profile) api.TranscodeOptions {
+	source := item.PrimarySource()
+	req := api.TranscodeOptions{
+		Audio:         item.Type == "Audio",
+		MediaSourceID: source.Id,
+		Container:     p.Container,
+		VideoCodec:    p.VideoCodec,
+		AudioCodec:    p.AudioCodec,
+		MaxBitrate:    p.MaxBitrate,
+		MaxWidth:      p.MaxWidth,
+		MaxHeight:     p.MaxHeight,
+	}
+	if p.AudioLanguage != "" {
+		want := subtitles.NormalizeLanguage(p.AudioLanguage)
+		for _, stream := range source.MediaStreams {
+			if stream.Type == "Audio" && subtitles.NormalizeLanguage(stream.Language) == want {
+				index := stream.Index
+				req.AudioStreamIndex = &index
+				break
+			}
+		}
+		if req.AudioStreamIndex == nil {
+			printError("no %s audio track for %s, using server default\n", p.AudioLanguage, item.Name)
+		}
+	}
+	return req
+}
+
+func profileFromRecord(value string) (*transcode.Profile, error) {
+	if value == "" {
+		return nil, nil
+	}
+	p, err := transcode.Lookup(value)
+	if err != nil {
+		return nil, fmt.Errorf("stored profile %q: %w", value, err)
+	}
+	return &p, nil
+}
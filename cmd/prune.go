@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/retention"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+const lookupBatchSize = 100
+
+var pruneDryRun bool
+
+// sideFileExtensions are files written next to a download (subtitles,
+// lyrics) that are removed together with it.
+var sideFileExtensions = map[string]bool{
+	".srt": true,
+	".ass": true,
+	".ssa": true,
+	".vtt": true,
+	".sub": true,
+	".lrc": true,
+}
+
+var downloadsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete downloads according to the retention rules in the config",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		if cfg.Retention == nil {
+			printInfo("No retention rules configured\n")
+			return nil
+		}
+		var maxTotal int64
+		if cfg.Retention.MaxTotalSize != "" {
+			maxTotal, err = versions.ParseSize(cfg.Retention.MaxTotalSize)
+			if err != nil {
+				return exitError(2, fmt.Errorf("retention max_total_size: %w", err))
+			}
+		}
+
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		downloads, err := storeDB.ListDownloads("done")
+		if err != nil {
+			return err
+		}
+		files, err := retentionFiles(client, downloads)
+		if err != nil {
+			return exitError(4, err)
+		}
+
+		removals := retention.Plan(files, cfg.Retention, maxTotal, time.Now())
+		if len(removals) == 0 {
+			printInfo("Nothing to prune\n")
+			return nil
+		}
+		var freed int64
+		for _, r := range removals {
+			if pruneDryRun {
+				printInfo("[dry-run] would remove %s (%s)\n", r.File.Path, r.Reason)
+				freed += r.File.Size
+				continue
+			}
+			if err := removeDownload(storeDB, r.File.DownloadID, r.File.Path); err != nil {
+				printError("removing %s failed: %v\n", r.File.Path, err)
+				continue
+			}
+			printInfo("Removed %s (%s)\n", r.File.Path, r.Reason)
+			freed += r.File.Size
+		}
+		printInfo("Freed %s\n", formatBytes(freed))
+		return nil
+	},
+}
+
+func init() {
+	downloadsPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without deleting anything")
+	downloadsCmd.AddCommand(downloadsPruneCmd)
+}
+
+// retentionFiles turns finished downloads whose file still exists into
+// retention candidates, looking up watch state and series names on the
+// server. Parts and extras use the watch state of their parent item.
+func retentionFiles(client *api.Client, downloads []store.Download) ([]retention.File, error) {
+	var (
+		files []retention.File
+		ids   []string
+		owner = map[int]string{}
+	)
+	seen := map[string]bool{}
+	for _, d := range downloads {
+		info, err := os.Stat(d.Path)
+		if err != nil {
+			continue
+		}
+		id := d.ItemID
+		if d.ParentID.Valid {
+			id = d.ParentID.String
+		}
+		owner[len(files)] = id
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		file := retention.File{
+			DownloadID:   d.ID,
+			Path:         d.Path,
+			Size:         info.Size(),
+			DownloadedAt: d.UpdatedAt,
+		}
+		if d.ItemType == "Episode" {
+			file.SeriesID = d.SeriesID.String
+			file.Season = int(d.SeasonNumber.Int64)
+			file.Episode = int(d.EpisodeNumber.Int64)
+		}
+		files = append(files, file)
+	}
+
+	items, err := lookupItems(client, ids, []string{"Path"})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range files {
+		item, ok := items[owner[i]]
+		if !ok {
+			continue
+		}
+		files[i].Played = item.UserData != nil && item.UserData.Played
+		files[i].SeriesName = item.SeriesName
+	}
+	return files, nil
+}
+
+// lookupItems fetches items by ID with their user data, in batches.
+func lookupItems(client *api.Client, ids []string, fields []string) (map[string]api.Item, error) {
+	items := map[string]api.Item{}
+	for start := 0; start < len(ids); start += lookupBatchSize {
+		end := start + lookupBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		found, err := client.QueryAllItems(ctx, api.ItemQuery{IDs: ids[start:end], Fields: fields, UserData: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range found {
+			items[item.Id] = item
+		}
+	}
+	return items, nil
+}
+
+// removeDownload deletes a downloaded file together with its subtitle and
+// lyric side files and marks the store row as removed.
+func removeDownload(storeDB *store.Store, id int64, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	prefix := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "."
+	if entries, err := os.ReadDir(filepath.Dir(path)); err == nil {
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, prefix) && sideFileExtensions[strings.ToLower(filepath.Ext(name))] {
+				_ = os.Remove(filepath.Join(filepath.Dir(path), name))
+			}
+		}
+	}
+	return storeDB.SetDownloadStatus(id, "removed", "")
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/livetv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordingsTimer string
+	recordingTimers []string
+)
+
+var recordingsCmd = &cobra.Command{
+	Use:   "recordings",
+	Short: "Browse Live TV recordings",
+}
+
+var recordingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List finished Live TV recordings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, _, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		recordings, err := client.Recordings(ctx, recordingsTimer)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if jsonOutput {
+			outputJSON(recordings)
+			return nil
+		}
+		for _, r := range recordings {
+			fmt.Printf("%s\t%s\t%s\t%s\n", r.Id, recordingTitle(r), r.ChannelName, r.StartDate)
+		}
+		return nil
+	},
+}
+
+var recordingsTimersCmd = &cobra.Command{
+	Use:   "timers",
+	Short: "List Live TV series timers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, _, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		timers, err := client.SeriesTimers(ctx)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if jsonOutput {
+			outputJSON(timers)
+			return nil
+		}
+		for _, t := range timers {
+			fmt.Printf("%s\t%s\t%s\n", t.Id, t.Name, t.ChannelName)
+		}
+		return nil
+	},
+}
+
+var downloadRecordingCmd = &cobra.Command{
+	Use:   "recording",
+	Short: "Download a Live TV recording, or all new recordings of series timers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var recordingID string
+		if len(recordingTimers) == 0 {
+			id, err := resolveItemID(cmd, args, "Recording")
+			if err != nil {
+				return err
+			}
+			recordingID = id
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		var recordings []api.Item
+		if recordingID != "" {
+			item, err := client.GetItem(ctx, recordingID)
+			if err != nil {
+				return exitError(4, err)
+			}
+			recordings = append(recordings, *item)
+		}
+		for _, timerID := range recordingTimers {
+			items, err := client.Recordings(ctx, timerID)
+			if err != nil {
+				return exitError(4, err)
+			}
+			recordings = append(recordings, items...)
+		}
+		if len(recordings) == 0 {
+			printInfo("No recordings found\n")
+			return nil
+		}
+		return runDownloadRecordings(client, storeDir, recordings, opts)
+	},
+}
+
+func init() {
+	recordingsListCmd.Flags().StringVar(&recordingsTimer, "series-timer", "", "Only recordings of this series timer")
+	recordingsCmd.AddCommand(recordingsListCmd)
+	recordingsCmd.AddCommand(recordingsTimersCmd)
+	rootCmd.AddCommand(recordingsCmd)
+
+	downloadRecordingCmd.Flags().String("id", "", "Recording item ID")
+	downloadRecordingCmd.Flags().StringArrayVar(&recordingTimers, "series-timer", nil, "Download every recording of this series timer that is not downloaded yet (repeatable)")
+	downloadCmd.AddCommand(downloadRecordingCmd)
+}
+
+// runDownloadRecordings stores recordings as
+// "<output>/Recordings/<Program>/<Program> - <Channel> - <air time>.ext".
+// Recordings that were downloaded before are skipped.
+func runDownloadRecordings(client *api.Client, storeDir string, recordings []api.Item, opts downloadOptions) error {
+	storeDB, outputDir, limiter, err := openDownloadTarget(storeDir, opts)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	for _, rec := range recordings {
+		done, err := itemDownloaded(storeDB, rec)
+		if err != nil {
+			return err
+		}
+		if done {
+			printInfo("Already downloaded %s\n", recordingTitle(rec))
+			continue
+		}
+		recOpts := opts
+		if opts.Naming.Layout != layoutMirror {
+			recOpts.OverridePath = recordingPath(outputDir, rec, opts.Naming)
+		}
+		if err := downloadItem(client, storeDB, rec, outputDir, limiter, recOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recordingPath(root string, item api.Item, naming namingOptions) string {
+	program := item.Name
+	if item.SeriesName != "" {
+		program = item.SeriesName
+	}
+	start, _ := livetv.ParseStart(item.StartDate)
+	if !start.IsZero() {
+		start = start.Local()
+	}
+	name := livetv.FileName(program, recordingEpisode(item, program), item.ChannelName, start)
+
+	ext := filepath.Ext(item.PrimarySource().Path)
+	if ext == "" {
+		ext = ".ts"
+	}
+	if naming.Extension != "" {
+		ext = naming.Extension
+	}
+	folder := download.SanitizeFileName(program)
+	return filepath.Join(root, "Recordings", folder, download.SanitizeFileName(name)+ext)
+}
+
+func recordingEpisode(item api.Item, program string) string {
+	if item.EpisodeTitle != "" {
+		return item.EpisodeTitle
+	}
+	if item.Name != program {
+		return item.Name
+	}
+	return ""
+}
+
+func recordingTitle(item api.Item) string {
+	if item.SeriesName != "" && item.SeriesName != item.Name {
+		return item.SeriesName + " - " + item.Name
+	}
+	return item.Name
+}
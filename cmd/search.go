@@ -88,7 +88,7 @@ var searchCmd = &cobra.Command{
 }
 
 func init() {
-	searchCmd.Flags().StringVar(&searchType, "type", "", "Item type filter: movie, series, episode")
+	searchCmd.Flags().StringVar(&searchType, "type", "", "Item type filter: movie, series, episode, album, artist, track, collection, book, audiobook")
 	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Max results")
 	searchCmd.Flags().BoolVarP(&searchInteractive, "interactive", "i", false, "Interactive search UI")
 	rootCmd.AddCommand(searchCmd)
@@ -111,6 +111,18 @@ func parseSearchTypes(value string) []string {
 			out = append(out, "Series")
 		case "episode", "episodes":
 			out = append(out, "Episode")
+		case "album", "albums":
+			out = append(out, "MusicAlbum")
+		case "artist", "artists":
+			out = append(out, "MusicArtist")
+		case "track", "tracks", "song", "songs", "audio":
+			out = append(out, "Audio")
+		case "collection", "collections", "boxset":
+			out = append(out, "BoxSet")
+		case "book", "books":
+			out = append(out, "Book")
+		case "audiobook", "audiobooks":
+			out = append(out, "AudioBook")
 		}
 	}
 	if len(out) == 0 {
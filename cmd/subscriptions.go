@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/episodes"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subscribeFrom       string
+	subscribeKeepLatest int
+	subscriptionsQueue  bool
+)
+
+type subscriptionInfo struct {
+	SeriesID    string `json:"series_id"`
+	SeriesName  string `json:"series_name"`
+	From        string `json:"from,omitempty"`
+	KeepLatest  int64  `json:"keep_latest,omitempty"`
+	LastChecked string `json:"last_checked,omitempty"`
+}
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Short: "Follow a series and download new episodes with `subscriptions check`",
+}
+
+var subscribeSeriesCmd = &cobra.Command{
+	Use:   "series",
+	Short: "Subscribe to a series",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seriesID, err := resolveItemID(cmd, args, "Series")
+		if err != nil {
+			return err
+		}
+		sub := &store.Subscription{SeriesID: seriesID}
+		if subscribeFrom != "" {
+			from, err := episodes.ParseRef(subscribeFrom)
+			if err != nil {
+				return exitError(2, fmt.Errorf("--from: %w", err))
+			}
+			sub.FromSeason = sql.NullInt64{Int64: int64(from.Season), Valid: true}
+			sub.FromEpisode = sql.NullInt64{Int64: int64(from.Episode), Valid: true}
+		}
+		if subscribeKeepLatest < 0 {
+			return exitError(2, fmt.Errorf("--keep-latest must not be negative"))
+		}
+		sub.KeepLatest = sqlNullInt(subscribeKeepLatest)
+
+		client, _, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		series, err := client.GetItem(ctx, seriesID)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if series.Type != "Series" {
+			return exitError(2, fmt.Errorf("%s is a %s, not a series", series.Name, series.Type))
+		}
+		sub.SeriesName = series.Name
+
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+		if err := storeDB.AddSubscription(sub); err != nil {
+			return err
+		}
+		printInfo("Subscribed to %s\n", series.Name)
+		return nil
+	},
+}
+
+var subscriptionsCmd = &cobra.Command{
+	Use:   "subscriptions",
+	Short: "Manage series subscriptions",
+}
+
+var subscriptionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List series subscriptions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, storeDir, err := getClient(false)
+		if err != nil {
+			return err
+		}
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		subs, err := storeDB.ListSubscriptions()
+		if err != nil {
+			return err
+		}
+		infos := make([]subscriptionInfo, 0, len(subs))
+		for _, sub := range subs {
+			info := subscriptionInfo{
+				SeriesID:    sub.SeriesID,
+				SeriesName:  sub.SeriesName,
+				KeepLatest:  sub.KeepLatest.Int64,
+				LastChecked: sub.LastChecked.String,
+			}
+			if sub.FromSeason.Valid {
+				info.From = episodes.Ref{Season: int(sub.FromSeason.Int64), Episode: int(sub.FromEpisode.Int64)}.String()
+			}
+			infos = append(infos, info)
+		}
+
+		if jsonOutput {
+			outputJSON(infos)
+			return nil
+		}
+		for _, info := range infos {
+			fmt.Printf("%s\t%s\t%s\t%d\t%s\n", info.SeriesID, info.SeriesName, info.From, info.KeepLatest, info.LastChecked)
+		}
+		return nil
+	},
+}
+
+var subscriptionsRemoveCmd = &cobra.Command{
+	Use:   "remove <seriesId>",
+	Short: "Remove a series subscription",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, storeDir, err := getClient(false)
+		if err != nil {
+			return err
+		}
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		removed, err := storeDB.RemoveSubscription(args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return exitError(2, fmt.Errorf("no subscription for %s", args[0]))
+		}
+		printInfo("Removed subscription %s\n", args[0])
+		return nil
+	},
+}
+
+var subscriptionsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Download (or queue) new episodes of all subscribed series",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		opts, err := newDownloadOptions(cfg)
+		if err != nil {
+			return err
+		}
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		subs, err := storeDB.ListSubscriptions()
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			printInfo("No subscriptions\n")
+			return nil
+		}
+
+		var firstErr error
+		for _, sub := range subs {
+			if err := checkSubscription(client, storeDB, storeDir, sub, opts, subscriptionsQueue); err != nil {
+				printError("%s: %v\n", sub.SeriesName, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if !opts.DryRun {
+				_ = storeDB.MarkSubscriptionChecked(sub.SeriesID)
+			}
+		}
+		return firstErr
+	},
+}
+
+func init() {
+	subscribeSeriesCmd.Flags().String("id", "", "Series item ID")
+	subscribeSeriesCmd.Flags().StringVar(&subscribeFrom, "from", "", "First episode to download, e.g. S03E01")
+	subscribeSeriesCmd.Flags().IntVar(&subscribeKeepLatest, "keep-latest", 0, "Keep only the newest N episodes on disk (0 keeps all)")
+	subscribeCmd.AddCommand(subscribeSeriesCmd)
+	rootCmd.AddCommand(subscribeCmd)
+
+	subscriptionsCheckCmd.Flags().BoolVar(&subscriptionsQueue, "queue", false, "Only queue new episodes for `downloads resume`")
+	subscriptionsCheckCmd.Flags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
+	subscriptionsCheckCmd.Flags().StringVar(&downloadOutput, "output", "", "Output directory (default: store/downloads)")
+	subscriptionsCheckCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show planned downloads without downloading")
+	subscriptionsCheckCmd.Flags().BoolVar(&checkUpgradesFlag, "check-upgrades", false, "Re-download episodes the server has replaced")
+	subscriptionsCmd.AddCommand(subscriptionsListCmd)
+	subscriptionsCmd.AddCommand(subscriptionsRemoveCmd)
+	subscriptionsCmd.AddCommand(subscriptionsCheckCmd)
+	rootCmd.AddCommand(subscriptionsCmd)
+}
+
+// checkSubscription downloads (or, with queue, queues) the episodes of a
+// subscribed series that are newer than the recorded series progress or
+// were never downloaded, then enforces --keep-latest.
+func checkSubscription(client *api.Client, storeDB *store.Store, storeDir string, sub store.Subscription, opts downloadOptions, queue bool) error {
+	items, err := client.SeriesEpisodes(ctx, sub.SeriesID)
+	if err != nil {
+		return exitError(4, err)
+	}
+	items = availableEpisodes(items)
+	if sub.FromSeason.Valid {
+		from := [2]int64{sub.FromSeason.Int64, sub.FromEpisode.Int64}
+		var kept []api.Item
+		for _, ep := range items {
+			if compareEpisode(ep, from) >= 0 {
+				kept = append(kept, ep)
+			}
+		}
+		items = kept
+	}
+	if n := int(sub.KeepLatest.Int64); n > 0 && len(items) > n {
+		items = items[len(items)-n:]
+	}
+
+	lastSeason, lastEpisode, hasProgress, err := storeDB.SeriesProgress(sub.SeriesID)
+	if err != nil {
+		return err
+	}
+	var wanted []api.Item
+	for _, ep := range items {
+		done, err := alreadyDownloaded(storeDB, ep.Id)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+		newer := !hasProgress || compareEpisode(ep, [2]int64{lastSeason, lastEpisode}) > 0
+		if !newer {
+			previous, err := storeDB.WasDownloaded(ep.Id)
+			if err != nil {
+				return err
+			}
+			if previous {
+				// Downloaded before and deleted locally or pruned since.
+				continue
+			}
+		}
+		wanted = append(wanted, ep)
+	}
+
+	if len(wanted) == 0 {
+		printInfo("%s: no new episodes\n", sub.SeriesName)
+	} else {
+		printInfo("%s: %d new episodes\n", sub.SeriesName, len(wanted))
+		opts.Series = sub.SeriesID
+		if queue {
+			if !opts.DryRun {
+				if err := queueDownloads(client, storeDB, wanted, resolveOutputDir(storeDir, opts), opts); err != nil {
+					return err
+				}
+			}
+		} else if err := runDownloadItems(client, storeDir, wanted, opts); err != nil {
+			return err
+		}
+	}
+
+	if sub.KeepLatest.Int64 > 0 {
+		if err := removeOldEpisodes(storeDB, sub, items, opts.DryRun); err != nil {
+			return err
+		}
+	}
+	if checkUpgradesFlag {
+		downloads, err := storeDB.SeriesDownloads(sub.SeriesID)
+		if err != nil {
+			return err
+		}
+		limiter, err := download.ParseRateLimit(opts.Rate)
+		if err != nil {
+			return exitError(2, err)
+		}
+		if _, err := checkUpgrades(client, storeDB, downloads, limiter, opts.DryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeOldEpisodes deletes downloaded episodes of the series that are not
+// among the newest ones kept by the subscription.
+func removeOldEpisodes(storeDB *store.Store, sub store.Subscription, latest []api.Item, dryRun bool) error {
+	keep := map[string]bool{}
+	for _, ep := range latest {
+		keep[ep.Id] = true
+	}
+	downloads, err := storeDB.SeriesDownloads(sub.SeriesID)
+	if err != nil {
+		return err
+	}
+	for _, d := range downloads {
+		if keep[d.ItemID] {
+			continue
+		}
+		if dryRun {
+			printInfo("[dry-run] would remove %s\n", d.Path)
+			continue
+		}
+		if err := removeDownload(storeDB, d.ID, d.Path); err != nil {
+			return err
+		}
+		printInfo("Removed %s\n", d.Path)
+	}
+	return nil
+}
+
+// availableEpisodes drops episodes that only exist as metadata (missing or
+// not yet aired) and sorts the rest by season and episode.
+func availableEpisodes(episodes []api.Item) []api.Item {
+	var out []api.Item
+	for _, ep := range episodes {
+		if ep.LocationType == "Virtual" {
+			continue
+		}
+		out = append(out, ep)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return compareEpisode(out[i], [2]int64{int64(out[j].ParentIndexNumber), int64(out[j].IndexNumber)}) < 0
+	})
+	return out
+}
+
+func compareEpisode(ep api.Item, other [2]int64) int {
+	season, episode := int64(ep.ParentIndexNumber), int64(ep.IndexNumber)
+	switch {
+	case season != other[0]:
+		if season < other[0] {
+			return -1
+		}
+		return 1
+	case episode != other[1]:
+		if episode < other[1] {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
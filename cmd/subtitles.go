@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/subtitles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subsLanguages string
+	subsFormat    string
+	subsItemID    string
+	subsSeriesID  string
+)
+
+type subtitleOptions struct {
+	Selection subtitles.Selection
+	Format    string
+}
+
+var downloadSubtitlesCmd = &cobra.Command{
+	Use:   "subtitles",
+	Short: "Download subtitles for already downloaded items",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		subs, err := resolveSubtitles()
+		if err != nil {
+			return err
+		}
+		if !subs.Selection.Enabled() {
+			subs.Selection = subtitles.Selection{All: true}
+		}
+
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		records, err := storeDB.ListDownloads("done")
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, rec := range records {
+			if rec.ItemType != "Movie" && rec.ItemType != "Episode" {
+				continue
+			}
+			if subsItemID != "" && rec.ItemID != subsItemID {
+				continue
+			}
+			if subsSeriesID != "" && rec.SeriesID.String != subsSeriesID {
+				continue
+			}
+			if _, err := os.Stat(rec.Path); err != nil {
+				continue
+			}
+
+			item, err := client.GetItem(ctx, rec.ItemID)
+			if err != nil {
+				return exitError(4, err)
+			}
+			if dryRun {
+				printInfo("[dry-run] subtitles for %s\n", rec.Path)
+				continue
+			}
+			count += downloadSubtitles(client, *item, rec.Path, subs)
+		}
+
+		printInfo("Downloaded %d subtitle file(s)\n", count)
+		return nil
+	},
+}
+
+func init() {
+	downloadCmd.PersistentFlags().StringVar(&subsLanguages, "subs", "", "Subtitle languages to download (e.g. en,de or all)")
+	downloadCmd.PersistentFlags().StringVar(&subsFormat, "subs-format", "", "Subtitle format: srt, vtt or ass (default: keep text format, else srt)")
+
+	downloadSubtitlesCmd.Flags().StringVar(&subsItemID, "id", "", "Only this movie or episode item ID")
+	downloadSubtitlesCmd.Flags().StringVar(&subsSeriesID, "series", "", "Only episodes of this series ID")
+	downloadCmd.AddCommand(downloadSubtitlesCmd)
+}
+
+func resolveSubtitles() (subtitleOptions, error) {
+	sel, err := subtitles.ParseSelection(subsLanguages)
+	if err != nil {
+		return subtitleOptions{}, exitError(2, err)
+	}
+	if subsFormat != "" {
+		if _, err := subtitles.OutputFormat("", subsFormat); err != nil {
+			return subtitleOptions{}, exitError(2, err)
+		}
+	}
+	return subtitleOptions{Selection: sel, Format: subsFormat}, nil
+}
+
+// downloadSubtitles saves the matching subtitle streams of item next to
+// videoPath and returns how many files were written. Failures are reported
+// as warnings so they never fail the main download.
+func downloadSubtitles(client *api.Client, item api.Item, videoPath string, opts subtitleOptions) int {
+	source := item.PrimarySource()
+	sourceID := source.Id
+	if sourceID == "" {
+		sourceID = item.Id
+	}
+	base := strings.TrimSuffix(videoPath, filepath.Ext(videoPath))
+
+	written := 0
+	seen := map[string]bool{}
+	for _, stream := range source.MediaStreams {
+		if stream.Type != "Subtitle" || !opts.Selection.Matches(stream.Language) {
+			continue
+		}
+		if !subtitles.IsTextCodec(stream.Codec) {
+			printError("skipping image-based subtitle %q (%s) for %s\n", stream.DisplayTitle, stream.Codec, item.Name)
+			continue
+		}
+		format, err := subtitles.OutputFormat(stream.Codec, opts.Format)
+		if err != nil {
+			printError("%v\n", err)
+			continue
+		}
+
+		path := subtitles.FileName(base, stream.Language, stream.IsForced, stream.IsHearingImpaired, format)
+		if seen[path] {
+			path = subtitles.FileName(fmt.Sprintf("%s.%d", base, stream.Index), stream.Language, stream.IsForced, stream.IsHearingImpaired, format)
+		}
+		seen[path] = true
+
+		if err := saveSubtitle(client, item.Id, sourceID, stream.Index, format, path); err != nil {
+			printError("subtitle %s for %s failed: %v\n", subtitles.NormalizeLanguage(stream.Language), item.Name, err)
+			continue
+		}
+		written++
+	}
+	return written
+}
+
+func saveSubtitle(client *api.Client, itemID, sourceID string, index int, format, path string) error {
+	resp, err := client.OpenSubtitle(ctx, itemID, sourceID, index, format)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return saveResponse(resp.Body, path)
+}
+
+// saveResponse writes body to path through a temporary .part file so a
+// failed transfer never leaves a truncated file behind.
+func saveResponse(body io.Reader, path string) error {
+	tmp := path + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := download.CopyWithProgress(ctx, f, body, 0, nil, nil); err != nil {
+		f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
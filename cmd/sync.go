@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/episodes"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/syncplan"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/transcode"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncConfigPath string
+	syncEvery      time.Duration
+)
+
+// markedTypes are the item types favorites and tag rules look for. Marked
+// series and albums are expanded to their episodes and tracks.
+var markedTypes = []string{"Movie", "Series", "Episode", "MusicAlbum", "Audio"}
+
+// syncResolvers fetch the items a rule of the given type selects.
+var syncResolvers = map[string]func(*api.Client, config.SyncRule) ([]api.Item, error){
+	"series":     resolveSyncSeries,
+	"movie":      resolveSyncItem,
+	"episode":    resolveSyncItem,
+	"collection": resolveSyncCollection,
+	"playlist":   resolveSyncPlaylist,
+	"library":    resolveSyncLibrary,
+	"favorites":  resolveSyncFavorites,
+	"tag":        resolveSyncTag,
+}
+
+// syncTarget is a resolved rule: the items it selects and the download
+// options derived from its quality settings.
+type syncTarget struct {
+	rule    config.SyncRule
+	opts    downloadOptions
+	items   []api.Item
+	missing []api.Item
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror the items selected by the sync rules: download what is missing, delete what no longer matches",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		if syncEvery <= 0 {
+			return runSync(client, cfg, storeDir)
+		}
+		for {
+			if err := runSync(client, cfg, storeDir); err != nil {
+				printError("sync failed: %v\n", err)
+			}
+			time.Sleep(syncEvery)
+		}
+	},
+}
+
+// runSync resolves every rule, downloads the selected items that are
+// missing and deletes the downloads no rule selects anymore.
+func runSync(client *api.Client, cfg *config.Config, storeDir string) error {
+	sc, err := loadSyncConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if sc == nil || len(sc.Rules) == 0 {
+		printInfo("No sync rules configured\n")
+		return nil
+	}
+	if downloadOutput == "" {
+		downloadOutput = sc.Output
+	}
+	base, err := newDownloadOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]*syncTarget, 0, len(sc.Rules))
+	keys := map[string]bool{}
+	for i, rule := range sc.Rules {
+		opts, err := syncRuleOptions(rule, base)
+		if err != nil {
+			return exitError(2, fmt.Errorf("sync rule %d: %w", i+1, err))
+		}
+		if keys[rule.Key()] {
+			return exitError(2, fmt.Errorf("sync rule %d: duplicate rule %q (set a unique name)", i+1, rule.Key()))
+		}
+		keys[rule.Key()] = true
+		targets = append(targets, &syncTarget{rule: rule, opts: opts})
+	}
+
+	storeDB, err := store.Open(storeDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	var firstErr error
+	selected := map[string]map[string]bool{}
+	failed := map[string]bool{}
+	now := time.Now()
+	for _, t := range targets {
+		key := t.rule.Key()
+		items, err := syncResolvers[t.rule.Type](client, t.rule)
+		if err != nil {
+			printError("%s: %v\n", key, err)
+			failed[key] = true
+			if firstErr == nil {
+				firstErr = exitError(4, err)
+			}
+			continue
+		}
+		t.items = syncplan.Apply(items, t.rule, now)
+		selected[key] = map[string]bool{}
+		for _, item := range t.items {
+			selected[key][item.Id] = true
+			done, err := itemDownloaded(storeDB, item)
+			if err != nil {
+				return err
+			}
+			if !done {
+				t.missing = append(t.missing, item)
+			}
+		}
+		printInfo("%s: %d selected, %d to download\n", key, len(t.items), len(t.missing))
+	}
+
+	tracked, err := storeDB.SyncItems()
+	if err != nil {
+		return err
+	}
+	keepFiles := map[string]bool{}
+	for _, t := range targets {
+		if t.rule.KeepsFiles() {
+			keepFiles[t.rule.Key()] = true
+		}
+	}
+	stale, remove := syncplan.Stale(tracked, selected, failed, keepFiles)
+
+	if base.DryRun {
+		for _, t := range targets {
+			for _, item := range t.missing {
+				printInfo("[dry-run] would download %s (%s)\n", item.Name, t.rule.Key())
+			}
+		}
+		for _, item := range stale {
+			if !remove[item.ItemID] {
+				continue
+			}
+			downloads, err := storeDB.ItemDownloads(item.ItemID)
+			if err != nil {
+				return err
+			}
+			for _, d := range downloads {
+				printInfo("[dry-run] would remove %s (%s)\n", d.Path, item.Rule)
+			}
+		}
+		if checkUpgradesFlag {
+			if err := syncUpgrades(client, storeDB, targets, base); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	downloaded, removed, kept := 0, 0, 0
+	for _, t := range targets {
+		if len(t.missing) > 0 {
+			if err := runDownloadItems(client, storeDir, t.missing, t.opts); err != nil {
+				printError("%s: %v\n", t.rule.Key(), err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		missing := map[string]bool{}
+		for _, item := range t.missing {
+			missing[item.Id] = true
+		}
+		for _, item := range t.items {
+			done, err := itemDownloaded(storeDB, item)
+			if err != nil {
+				return err
+			}
+			if !done {
+				continue
+			}
+			if err := storeDB.SetSyncItem(t.rule.Key(), item.Id, item.Name); err != nil {
+				return err
+			}
+			if missing[item.Id] {
+				downloaded++
+			} else {
+				kept++
+			}
+		}
+	}
+
+	for _, item := range stale {
+		if remove[item.ItemID] {
+			downloads, err := storeDB.ItemDownloads(item.ItemID)
+			if err != nil {
+				return err
+			}
+			for _, d := range downloads {
+				if err := removeDownload(storeDB, d.ID, d.Path); err != nil {
+					printError("removing %s failed: %v\n", d.Path, err)
+					continue
+				}
+				printInfo("Removed %s (%s)\n", d.Path, item.Rule)
+				removed++
+			}
+			delete(remove, item.ItemID)
+		}
+		if err := storeDB.RemoveSyncItem(item.Rule, item.ItemID); err != nil {
+			return err
+		}
+	}
+
+	if checkUpgradesFlag {
+		if err := syncUpgrades(client, storeDB, targets, base); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	printInfo("Sync finished: %d downloaded, %d files removed, %d already in sync\n", downloaded, removed, kept)
+	return firstErr
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncConfigPath, "config", "", "Sync rules file (default: the \"sync\" section of the config)")
+	syncCmd.Flags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
+	syncCmd.Flags().StringVar(&downloadOutput, "output", "", "Output directory (default: the rules' output, else store/downloads)")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without downloading or deleting anything")
+	syncCmd.Flags().BoolVar(&checkUpgradesFlag, "check-upgrades", false, "Re-download files the server has replaced")
+	syncCmd.Flags().DurationVar(&syncEvery, "every", 0, "Keep running and sync again at this interval (e.g. 15m)")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// loadSyncConfig returns the rules from --config, or the "sync" section of
+// the config.
+func loadSyncConfig(cfg *config.Config) (*config.SyncConfig, error) {
+	if syncConfigPath == "" {
+		return cfg.Sync, nil
+	}
+	sc, err := config.LoadSync(syncConfigPath)
+	if err != nil {
+		return nil, exitError(2, err)
+	}
+	return sc, nil
+}
+
+// syncUpgrades replaces the downloads of selected items that changed on
+// the server.
+func syncUpgrades(client *api.Client, storeDB *store.Store, targets []*syncTarget, opts downloadOptions) error {
+	var downloads []store.Download
+	for _, t := range targets {
+		for _, item := range t.items {
+			rows, err := storeDB.ItemDownloads(item.Id)
+			if err != nil {
+				return err
+			}
+			downloads = append(downloads, rows...)
+		}
+	}
+	limiter, err := download.ParseRateLimit(opts.Rate)
+	if err != nil {
+		return exitError(2, err)
+	}
+	_, err = checkUpgrades(client, storeDB, downloads, limiter, opts.DryRun)
+	return err
+}
+
+// syncRuleOptions validates a rule and applies its quality settings to the
+// base download options.
+func syncRuleOptions(rule config.SyncRule, base downloadOptions) (downloadOptions, error) {
+	opts := base
+	if _, ok := syncResolvers[rule.Type]; !ok {
+		return opts, fmt.Errorf("unknown type %q (use %s)", rule.Type, strings.Join(syncRuleTypes(), ", "))
+	}
+	switch {
+	case rule.Type == "tag" && rule.Tag == "":
+		return opts, fmt.Errorf("tag rule needs a tag")
+	case rule.Type != "tag" && rule.Tag != "":
+		return opts, fmt.Errorf("tag only applies to tag rules")
+	case rule.ID == "" && rule.Type != "tag" && rule.Type != "favorites":
+		return opts, fmt.Errorf("%s rule needs an id", rule.Type)
+	}
+	if rule.Episodes != "" {
+		if rule.Type != "series" {
+			return opts, fmt.Errorf("episodes only applies to series rules")
+		}
+		if _, err := episodes.Parse(rule.Episodes); err != nil {
+			return opts, err
+		}
+	}
+	if len(rule.Types) > 0 {
+		if rule.Type != "library" {
+			return opts, fmt.Errorf("types only applies to library rules")
+		}
+		if _, err := parseLibraryTypes(strings.Join(rule.Types, ",")); err != nil {
+			return opts, err
+		}
+	}
+	if rule.Retention.KeepLatest < 0 || rule.Retention.MaxAgeDays < 0 {
+		return opts, fmt.Errorf("retention values must not be negative")
+	}
+	if rule.Profile != "" {
+		profile, err := transcode.Lookup(rule.Profile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Profile = &profile
+		opts.Naming.Extension = profile.Extension()
+	}
+	if rule.Version != "" {
+		sel, err := versions.Parse(rule.Version)
+		if err != nil {
+			return opts, err
+		}
+		opts.Version = sel
+	}
+	if rule.Type == "series" {
+		opts.Series = rule.ID
+	}
+	return opts, nil
+}
+
+func syncRuleTypes() []string {
+	var names []string
+	for name := range syncResolvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolveSyncSeries(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	items, err := client.SeriesEpisodes(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	items = availableEpisodes(items)
+	sel, err := episodes.Parse(rule.Episodes)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(items, episodes.AbsoluteNumbers(items)), nil
+}
+
+func resolveSyncItem(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	item, err := client.GetItem(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	return []api.Item{*item}, nil
+}
+
+func resolveSyncCollection(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	children, err := client.CollectionItems(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	var movies []api.Item
+	for _, child := range children {
+		if child.Type == "Movie" {
+			movies = append(movies, child)
+		}
+	}
+	return movies, nil
+}
+
+func resolveSyncPlaylist(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	return client.PlaylistItems(ctx, rule.ID)
+}
+
+func resolveSyncLibrary(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	types := "movie,episode,track"
+	if len(rule.Types) > 0 {
+		types = strings.Join(rule.Types, ",")
+	}
+	itemTypes, err := parseLibraryTypes(types)
+	if err != nil {
+		return nil, err
+	}
+	return client.QueryAllItems(ctx, api.ItemQuery{
+		ParentID:  rule.ID,
+		Types:     itemTypes,
+		Recursive: true,
+		SortBy:    []string{"SeriesSortName", "ParentIndexNumber", "IndexNumber", "SortName"},
+		UserData:  true,
+	})
+}
+
+func resolveSyncFavorites(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	favorite := true
+	return resolveMarked(client, api.ItemQuery{ParentID: rule.ID, IsFavorite: &favorite})
+}
+
+func resolveSyncTag(client *api.Client, rule config.SyncRule) ([]api.Item, error) {
+	return resolveMarked(client, api.ItemQuery{ParentID: rule.ID, Tags: []string{rule.Tag}})
+}
+
+// resolveMarked runs a favorites or tag query and replaces marked series
+// and albums with their episodes and tracks.
+func resolveMarked(client *api.Client, query api.ItemQuery) ([]api.Item, error) {
+	query.Types = markedTypes
+	query.Recursive = true
+	query.UserData = true
+	query.SortBy = []string{"SortName"}
+	marked, err := client.QueryAllItems(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []api.Item
+	seen := map[string]bool{}
+	add := func(items ...api.Item) {
+		for _, item := range items {
+			if !seen[item.Id] {
+				seen[item.Id] = true
+				out = append(out, item)
+			}
+		}
+	}
+	for _, item := range marked {
+		switch item.Type {
+		case "Series":
+			items, err := client.SeriesEpisodes(ctx, item.Id)
+			if err != nil {
+				return nil, err
+			}
+			add(availableEpisodes(items)...)
+		case "MusicAlbum":
+			tracks, err := client.AlbumTracks(ctx, item.Id)
+			if err != nil {
+				return nil, err
+			}
+			add(tracks...)
+		default:
+			add(item)
+		}
+	}
+	return out, nil
+}
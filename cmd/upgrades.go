@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/download"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/transcode"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/upgrades"
+	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+)
+
+var checkUpgradesFlag bool
+
+var downloadsCheckUpgradesCmd = &cobra.Command{
+	Use:   "check-upgrades",
+	Short: "Re-download files the server has replaced since they were downloaded",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		limiter, err := download.ParseRateLimit(resolveRate(cfg.DefaultRate))
+		if err != nil {
+			return exitError(2, err)
+		}
+		storeDB, err := store.Open(storeDir)
+		if err != nil {
+			return err
+		}
+		defer storeDB.Close()
+
+		downloads, err := storeDB.ListDownloads("done")
+		if err != nil {
+			return err
+		}
+		upgraded, err := checkUpgrades(client, storeDB, downloads, limiter, dryRun)
+		if err != nil {
+			return err
+		}
+		if upgraded == 0 {
+			printInfo("No upgrades found\n")
+		}
+		return nil
+	},
+}
+
+func init() {
+	downloadsCheckUpgradesCmd.Flags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
+	downloadsCheckUpgradesCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which downloads changed without downloading")
+	downloadsCmd.AddCommand(downloadsCheckUpgradesCmd)
+}
+
+// checkUpgrades compares finished downloads with the server's current media
+// sources and replaces the files that changed. It returns how many
+// downloads were (or, with dryRun, would be) upgraded.
+func checkUpgrades(client *api.Client, storeDB *store.Store, downloads []store.Download, limiter *rate.Limiter, dryRun bool) (int, error) {
+	var (
+		candidates []store.Download
+		ids        []string
+	)
+	seen := map[string]bool{}
+	rows := map[int64]bool{}
+	for _, d := range downloads {
+		if rows[d.ID] || !downloadComplete(&d) {
+			continue
+		}
+		rows[d.ID] = true
+		candidates = append(candidates, d)
+		if !seen[d.ItemID] {
+			seen[d.ItemID] = true
+			ids = append(ids, d.ItemID)
+		}
+	}
+	items, err := lookupItems(client, ids, []string{"Path", "MediaSources", "MediaStreams"})
+	if err != nil {
+		return 0, exitError(4, err)
+	}
+
+	upgraded := 0
+	var firstErr error
+	for _, d := range candidates {
+		item, ok := items[d.ItemID]
+		if !ok {
+			continue
+		}
+		reason, idx := upgrades.Check(d, item)
+		if reason == "" {
+			continue
+		}
+		if dryRun {
+			printInfo("[dry-run] would upgrade %s (%s)\n", d.Path, reason)
+			upgraded++
+			continue
+		}
+		if err := upgradeDownload(client, storeDB, d, item, idx, limiter); err != nil {
+			printError("upgrading %s failed: %v\n", d.Path, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		printInfo("Upgraded %s (%s)\n", item.Name, reason)
+		upgraded++
+	}
+	return upgraded, firstErr
+}
+
+// upgradeDownload downloads media source idx of item next to the existing
+// file as <name>.part and renames it over the old file, so the old copy
+// stays usable until the new one is complete. If the server now sends a
+// different file type, the new file gets the new extension and the old file
+// is deleted.
+func upgradeDownload(client *api.Client, storeDB *store.Store, d store.Download, item api.Item, idx int, limiter *rate.Limiter) error {
+	if idx > 0 {
+		sources := []api.MediaSource{item.MediaSources[idx]}
+		sources = append(sources, item.MediaSources[:idx]...)
+		item.MediaSources = append(sources, item.MediaSources[idx+1:]...)
+	}
+	var opts downloadOptions
+	if d.Profile.Valid {
+		profile, err := transcode.Lookup(d.Profile.String)
+		if err != nil {
+			return err
+		}
+		opts.Profile = &profile
+	}
+
+	resp, err := openItemStream(client, item, 0, opts)
+	if err != nil {
+		return exitError(5, err)
+	}
+	defer resp.Body.Close()
+
+	path := d.Path
+	if opts.Profile == nil {
+		if ext := filepath.Ext(filenameFromResponse(resp)); ext != "" && !strings.EqualFold(ext, filepath.Ext(path)) {
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + ext
+		}
+	}
+	partPath := path + ".part"
+	f, err := openDownloadFile(partPath, 0)
+	if err != nil {
+		return err
+	}
+
+	total := totalBytesFromResponse(resp, 0)
+	written, err := download.CopyWithProgress(ctx, f, resp.Body, total, limiter, func(written int64, total int64) {
+		if !quietMode {
+			printProgress(item.Name, written, total)
+		}
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(partPath)
+		return exitError(5, err)
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		_ = os.Remove(partPath)
+		return err
+	}
+
+	if path != d.Path {
+		if err := os.Remove(d.Path); err != nil && !os.IsNotExist(err) {
+			printError("removing %s failed: %v\n", d.Path, err)
+		}
+		if err := storeDB.UpdateDownloadPath(d.ID, path); err != nil {
+			return err
+		}
+		printInfo("Replaced %s with %s\n", d.Path, path)
+		d.Path = path
+	} else {
+		printInfo("Replaced %s\n", path)
+	}
+
+	record := d
+	record.BytesTotal = sql.NullInt64{Int64: written, Valid: true}
+	record.BytesDone = record.BytesTotal
+	record.Status = "done"
+	record.Error.Valid = false
+	setDownloadSource(&record, item)
+	if _, err := storeDB.UpsertDownload(&record); err != nil {
+		return err
+	}
+	return nil
+}
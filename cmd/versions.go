@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/versions"
+	"github.com/spf13/cobra"
+)
+
+var mediaVersion string
+
+type versionInfo struct {
+	Index      int    `json:"index"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Container  string `json:"container"`
+	Size       int64  `json:"size"`
+	Resolution string `json:"resolution"`
+	HDR        string `json:"hdr"`
+	VideoCodec string `json:"video_codec"`
+	AudioCodec string `json:"audio_codec"`
+}
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions <itemId>",
+	Short: "List the alternate versions (media sources) of a movie or episode",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, _, _, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		item, err := client.GetItem(ctx, args[0])
+		if err != nil {
+			return exitError(4, err)
+		}
+
+		infos := make([]versionInfo, 0, len(item.MediaSources))
+		for i, src := range item.MediaSources {
+			infos = append(infos, versionInfo{
+				Index:      i + 1,
+				Id:         src.Id,
+				Name:       src.Name,
+				Container:  src.Container,
+				Size:       src.Size,
+				Resolution: src.Resolution(),
+				HDR:        src.HDRType(),
+				VideoCodec: src.VideoCodec(),
+				AudioCodec: src.AudioCodec(),
+			})
+		}
+
+		if jsonOutput {
+			outputJSON(infos)
+			return nil
+		}
+		for _, v := range infos {
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\t%s/%s\t%s\n", v.Index, v.Name, v.Resolution, v.HDR, v.Container, v.VideoCodec, v.AudioCodec, formatBytes(v.Size))
+		}
+		return nil
+	},
+}
+
+func init() {
+	downloadCmd.PersistentFlags().StringVar(&mediaVersion, "version", "", "Version to download: index, name, id=<sourceId>, or rules like 'prefer=1080p,sdr max-size=8G'")
+	rootCmd.AddCommand(versionsCmd)
+}
+
+func resolveVersion() (*versions.Selector, error) {
+	sel, err := versions.Parse(mediaVersion)
+	if err != nil {
+		return nil, exitError(2, err)
+	}
+	return sel, nil
+}
+
+// applyVersion moves the selected media source to the front of the item's
+// sources so it becomes the item's PrimarySource for the rest of the
+// download.
+func applyVersion(item api.Item, sel *versions.Selector) (api.Item, error) {
+	if sel == nil || len(item.MediaSources) == 0 {
+		return item, nil
+	}
+	idx, err := sel.Select(item.MediaSources)
+	if err != nil {
+		return item, fmt.Errorf("%s: %w", item.Name, err)
+	}
+	if idx == 0 {
+		return item, nil
+	}
+	sources := make([]api.MediaSource, 0, len(item.MediaSources))
+	sources = append(sources, item.MediaSources[idx])
+	sources = append(sources, item.MediaSources[:idx]...)
+	sources = append(sources, item.MediaSources[idx+1:]...)
+	item.MediaSources = sources
+	return item, nil
+}
+
+func versionName(item api.Item) string {
+	if len(item.MediaSources) < 2 {
+		return ""
+	}
+	src := item.PrimarySource()
+	if name := strings.TrimSpace(src.Name); name != "" {
+		return name
+	}
+	return src.Id
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/socket"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/syncplan"
+	"github.com/spf13/cobra"
+)
+
+// watchSettle is how long watch collects further changes after the first
+// one before handling them, since Jellyfin reports a new season or a
+// library scan in several messages.
+const watchSettle = 5 * time.Second
+
+var watchQueue bool
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Listen for library changes and download new items matching subscriptions and sync rules",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		if _, err := loadSyncConfig(cfg); err != nil {
+			return err
+		}
+		if _, err := newDownloadOptions(cfg); err != nil {
+			return err
+		}
+		socketURL, err := client.SocketURL()
+		if err != nil {
+			return exitError(2, err)
+		}
+
+		pending := newChangeQueue()
+		go func() {
+			_ = socket.Run(ctx, socketURL, nil, pending.catchUp, func(msg socket.Message) {
+				pending.add(changedItemIDs(msg, client.UserID())...)
+			}, func(err error) {
+				printError("connection lost: %v (reconnecting)\n", err)
+			})
+		}()
+		printInfo("Watching %s for new items\n", cfg.Server)
+		processChanges(client, cfg, storeDir, pending, watchQueue)
+		return nil
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchQueue, "queue", false, "Only queue matching items for `downloads resume`")
+	watchCmd.Flags().StringVar(&syncConfigPath, "config", "", "Sync rules file (default: the \"sync\" section of the config)")
+	watchCmd.Flags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
+	watchCmd.Flags().StringVar(&downloadOutput, "output", "", "Output directory (default: the rules' output, else store/downloads)")
+	watchCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be downloaded without downloading")
+	rootCmd.AddCommand(watchCmd)
+}
+
+// changeQueue collects changed item IDs without ever blocking the sender,
+// so the socket read loop and webhook requests are not held up while a
+// download runs. IDs that arrive during a download are handled afterwards.
+type changeQueue struct {
+	mu    sync.Mutex
+	ids   []string
+	all   bool
+	ready chan struct{}
+}
+
+func newChangeQueue() *changeQueue {
+	return &changeQueue{ready: make(chan struct{}, 1)}
+}
+
+// add queues item IDs.
+func (q *changeQueue) add(ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	q.mu.Lock()
+	q.ids = append(q.ids, ids...)
+	q.mu.Unlock()
+	q.notify()
+}
+
+// catchUp queues a check of all subscriptions and sync rules, for changes
+// that were missed while disconnected.
+func (q *changeQueue) catchUp() {
+	q.mu.Lock()
+	q.all = true
+	q.mu.Unlock()
+	q.notify()
+}
+
+func (q *changeQueue) notify() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take returns and clears the queued IDs and catch-up request.
+func (q *changeQueue) take() ([]string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids, all := q.ids, q.all
+	q.ids, q.all = nil, false
+	return ids, all
+}
+
+// processChanges handles queued changes. After the first one it waits
+// watchSettle for more, so related changes are handled together.
+func processChanges(client *api.Client, cfg *config.Config, storeDir string, pending *changeQueue, queue bool) {
+	for range pending.ready {
+		time.Sleep(watchSettle)
+		ids, all := pending.take()
+		if len(ids) == 0 && !all {
+			continue
+		}
+		if err := handleChanges(client, cfg, storeDir, uniqueStrings(ids), all, queue); err != nil {
+			printError("%v\n", err)
+		}
+	}
+}
+
+// changedItemIDs returns the items a socket message reports as added to the
+// library, or as newly favorited by userID.
+func changedItemIDs(msg socket.Message, userID string) []string {
+	switch msg.MessageType {
+	case "LibraryChanged":
+		var data socket.LibraryChanged
+		if json.Unmarshal(msg.Data, &data) == nil {
+			return data.ItemsAdded
+		}
+	case "UserDataChanged":
+		var data socket.UserDataChanged
+		if json.Unmarshal(msg.Data, &data) != nil || data.UserId != userID {
+			return nil
+		}
+		var ids []string
+		for _, item := range data.UserDataList {
+			if item.IsFavorite {
+				ids = append(ids, item.ItemId)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// handleChanges checks the subscriptions of the series the items belong
+// to and downloads (or queues) the items the sync rules select. With all
+// set it checks every subscription and rule instead and fetches whatever
+// is missing.
+func handleChanges(client *api.Client, cfg *config.Config, storeDir string, ids []string, all, queue bool) error {
+	var items map[string]api.Item
+	if !all {
+		var err error
+		items, err = lookupItems(client, ids, nil)
+		if err != nil {
+			return exitError(4, err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+	}
+	changed := map[string]bool{}
+	series := map[string]bool{}
+	for _, item := range items {
+		changed[item.Id] = true
+		if item.Type == "Episode" && item.SeriesId != "" {
+			series[item.SeriesId] = true
+		}
+	}
+
+	sc, err := loadSyncConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if sc != nil && downloadOutput == "" {
+		downloadOutput = sc.Output
+	}
+	base, err := newDownloadOptions(cfg)
+	if err != nil {
+		return err
+	}
+	storeDB, err := store.Open(storeDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+
+	var firstErr error
+	fail := func(err error) {
+		printError("%v\n", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	subs, err := storeDB.ListSubscriptions()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if all || series[sub.SeriesID] {
+			if err := checkSubscription(client, storeDB, storeDir, sub, base, queue); err != nil {
+				fail(err)
+			}
+		}
+	}
+
+	if sc == nil {
+		return firstErr
+	}
+	for _, rule := range sc.Rules {
+		if !all && !syncRuleMayMatch(rule, items) {
+			continue
+		}
+		opts, err := syncRuleOptions(rule, base)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		selected, err := syncResolvers[rule.Type](client, rule)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		var matched []api.Item
+		for _, item := range syncplan.Apply(selected, rule, time.Now()) {
+			if all || changed[item.Id] || changed[item.SeriesId] || changed[item.AlbumId] {
+				matched = append(matched, item)
+			}
+		}
+		if err := enqueueSyncItems(client, storeDB, storeDir, rule, matched, opts, queue); err != nil {
+			fail(err)
+		}
+	}
+	return firstErr
+}
+
+// syncRuleMayMatch reports whether a rule could select any of the items,
+// to avoid resolving rules that cannot be affected.
+func syncRuleMayMatch(rule config.SyncRule, items map[string]api.Item) bool {
+	for _, item := range items {
+		switch rule.Type {
+		case "series":
+			if item.SeriesId == rule.ID {
+				return true
+			}
+		case "movie", "episode":
+			if item.Id == rule.ID {
+				return true
+			}
+		case "favorites":
+			if item.UserData != nil && item.UserData.IsFavorite {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueSyncItems downloads or queues the items selected by a sync rule
+// that are not downloaded yet and records the finished ones for the rule.
+func enqueueSyncItems(client *api.Client, storeDB *store.Store, storeDir string, rule config.SyncRule, selected []api.Item, opts downloadOptions, queue bool) error {
+	var items []api.Item
+	for _, item := range selected {
+		done, err := itemDownloaded(storeDB, item)
+		if err != nil {
+			return err
+		}
+		if !done {
+			items = append(items, item)
+		} else if !opts.DryRun {
+			if err := storeDB.SetSyncItem(rule.Key(), item.Id, item.Name); err != nil {
+				return err
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	printInfo("%s: %d new items\n", rule.Key(), len(items))
+	if queue {
+		if opts.DryRun {
+			return nil
+		}
+		return queueDownloads(client, storeDB, items, resolveOutputDir(storeDir, opts), opts)
+	}
+	if err := runDownloadItems(client, storeDir, items, opts); err != nil {
+		return err
+	}
+	if opts.DryRun {
+		return nil
+	}
+	for _, item := range items {
+		done, err := itemDownloaded(storeDB, item)
+		if err != nil {
+			return err
+		}
+		if done {
+			if err := storeDB.SetSyncItem(rule.Key(), item.Id, item.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
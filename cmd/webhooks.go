@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+// webhookTimeout bounds reading and answering a webhook request. Events are
+// only queued, so requests finish quickly.
+const webhookTimeout = 30 * time.Second
+
+var (
+	webhookAddr   string
+	webhookSecret string
+)
+
+var listenWebhooksCmd = &cobra.Command{
+	Use:   "listen-webhooks",
+	Short: "Receive ItemAdded events from the Jellyfin Webhook plugin and download matching items",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret := webhookSecret
+		if secret == "" {
+			secret = os.Getenv("JELLYFIN_WEBHOOK_SECRET")
+		}
+		if secret == "" {
+			return exitError(2, fmt.Errorf("a shared secret is required (--secret or JELLYFIN_WEBHOOK_SECRET)"))
+		}
+		client, cfg, storeDir, err := getClient(true)
+		if err != nil {
+			return err
+		}
+		if _, err := loadSyncConfig(cfg); err != nil {
+			return err
+		}
+		if _, err := newDownloadOptions(cfg); err != nil {
+			return err
+		}
+
+		pending := newChangeQueue()
+		go processChanges(client, cfg, storeDir, pending, watchQueue)
+
+		// The handler only queues the item, so the plugin gets its 202 at
+		// once even while a download is running.
+		server := &http.Server{
+			Addr: webhookAddr,
+			Handler: webhook.Handler(secret, func(event webhook.Event) {
+				printInfo("Item added: %s (%s)\n", event.Name, event.ItemType)
+				pending.add(event.ItemId)
+			}),
+			ReadHeaderTimeout: webhookTimeout,
+			ReadTimeout:       webhookTimeout,
+			WriteTimeout:      webhookTimeout,
+			IdleTimeout:       2 * webhookTimeout,
+		}
+		printInfo("Listening for webhooks on %s\n", webhookAddr)
+		if err := server.ListenAndServe(); err != nil {
+			return exitError(2, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	listenWebhooksCmd.Flags().StringVar(&webhookAddr, "addr", ":8099", "Address to listen on")
+	listenWebhooksCmd.Flags().StringVar(&webhookSecret, "secret", "", "Shared secret the webhook must send (default: $JELLYFIN_WEBHOOK_SECRET)")
+	listenWebhooksCmd.Flags().BoolVar(&watchQueue, "queue", false, "Only queue matching items for `downloads resume`")
+	listenWebhooksCmd.Flags().StringVar(&syncConfigPath, "config", "", "Sync rules file (default: the \"sync\" section of the config)")
+	listenWebhooksCmd.Flags().StringVar(&downloadRate, "rate", "", "Download rate limit (e.g. 5M, 500K)")
+	listenWebhooksCmd.Flags().StringVar(&downloadOutput, "output", "", "Output directory (default: the rules' output, else store/downloads)")
+	listenWebhooksCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be downloaded without downloading")
+	rootCmd.AddCommand(listenWebhooksCmd)
+}
@@ -16,6 +16,8 @@ import (
 const (
 	defaultClientName = "jellyfin-download"
 	defaultVersion    = "0.1"
+
+	itemFields = "Path,MediaSources,MediaStreams,DateCreated"
 )
 
 type Client struct {
@@ -53,6 +55,25 @@ func (c *Client) UserID() string {
 	return c.userID
 }
 
+// SocketURL returns the URL of the server's /socket WebSocket, authenticated
+// with the access token.
+func (c *Client) SocketURL() (string, error) {
+	u, err := url.Parse(c.baseURL + "/socket")
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	params := url.Values{}
+	params.Set("api_key", c.token)
+	params.Set("deviceId", c.deviceID)
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
 func (c *Client) AuthenticateByName(ctx context.Context, username, password string) (*AuthResponse, error) {
 	payload := map[string]string{
 		"Username": username,
@@ -116,6 +137,246 @@ func (c *Client) GetItem(ctx context.Context, itemID string) (*Item, error) {
 	return &resp, nil
 }
 
+func (c *Client) UserViews(ctx context.Context) ([]Item, error) {
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/Users/"+c.userID+"/Views", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// VirtualFolders returns the server's libraries with their physical
+// locations.
+func (c *Client) VirtualFolders(ctx context.Context) ([]VirtualFolder, error) {
+	var resp []VirtualFolder
+	if err := c.getJSON(ctx, "/Library/VirtualFolders", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) AdditionalParts(ctx context.Context, itemID string) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/Videos/"+itemID+"/AdditionalParts", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func (c *Client) LocalTrailers(ctx context.Context, itemID string) ([]Item, error) {
+	return c.itemList(ctx, "/Items/"+itemID+"/LocalTrailers")
+}
+
+func (c *Client) SpecialFeatures(ctx context.Context, itemID string) ([]Item, error) {
+	return c.itemList(ctx, "/Items/"+itemID+"/SpecialFeatures")
+}
+
+func (c *Client) ThemeMedia(ctx context.Context, itemID string) (*AllThemeMediaResult, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+
+	var resp AllThemeMediaResult
+	if err := c.getJSON(ctx, "/Items/"+itemID+"/ThemeMedia", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) itemList(ctx context.Context, endpoint string) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+
+	var resp []Item
+	if err := c.getJSON(ctx, endpoint, params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) AlbumTracks(ctx context.Context, albumID string) ([]Item, error) {
+	resp, err := c.QueryItems(ctx, ItemQuery{
+		ParentID:  albumID,
+		Types:     []string{"Audio"},
+		Recursive: true,
+		SortBy:    []string{"ParentIndexNumber", "IndexNumber", "SortName"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func (c *Client) ArtistAlbums(ctx context.Context, artistID string) ([]Item, error) {
+	resp, err := c.QueryItems(ctx, ItemQuery{
+		AlbumArtistIDs: []string{artistID},
+		Types:          []string{"MusicAlbum"},
+		Recursive:      true,
+		SortBy:         []string{"ProductionYear", "SortName"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func (c *Client) CollectionItems(ctx context.Context, collectionID string) ([]Item, error) {
+	resp, err := c.QueryItems(ctx, ItemQuery{
+		ParentID: collectionID,
+		SortBy:   []string{"ProductionYear", "SortName"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// FolderItems returns the direct children of a folder, photo album or
+// library.
+func (c *Client) FolderItems(ctx context.Context, folderID string) ([]Item, error) {
+	return c.QueryAllItems(ctx, ItemQuery{
+		ParentID: folderID,
+		SortBy:   []string{"SortName"},
+		Fields:   []string{"Path", "MediaSources", "DateCreated"},
+	})
+}
+
+// BookItems returns the books (or audiobook chapters) below a folder in
+// series and chapter order.
+func (c *Client) BookItems(ctx context.Context, parentID string, types []string) ([]Item, error) {
+	return c.QueryAllItems(ctx, ItemQuery{
+		ParentID:  parentID,
+		Types:     types,
+		Recursive: true,
+		SortBy:    []string{"SeriesSortName", "ParentIndexNumber", "IndexNumber", "SortName"},
+		Fields:    []string{"Path", "MediaSources", "People"},
+	})
+}
+
+func (c *Client) PlaylistItems(ctx context.Context, playlistID string) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+	params.Set("Fields", itemFields)
+
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/Playlists/"+playlistID+"/Items", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func (c *Client) Lyrics(ctx context.Context, itemID string) (*LyricsResponse, error) {
+	var resp LyricsResponse
+	if err := c.getJSON(ctx, "/Audio/"+itemID+"/Lyrics", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Recordings lists finished Live TV recordings, optionally only those of one
+// series timer.
+func (c *Client) Recordings(ctx context.Context, seriesTimerID string) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+	if seriesTimerID != "" {
+		params.Set("SeriesTimerId", seriesTimerID)
+	}
+	params.Set("IsInProgress", "false")
+	params.Set("Fields", itemFields)
+
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/LiveTv/Recordings", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+func (c *Client) SeriesTimers(ctx context.Context) ([]SeriesTimer, error) {
+	var resp SeriesTimersResponse
+	if err := c.getJSON(ctx, "/LiveTv/SeriesTimers", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// NextUp returns the next unwatched episode of every series the user is
+// watching, or of seriesID only.
+func (c *Client) NextUp(ctx context.Context, seriesID string, limit int) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+	if seriesID != "" {
+		params.Set("SeriesId", seriesID)
+	}
+	if limit > 0 {
+		params.Set("Limit", fmt.Sprintf("%d", limit))
+	}
+	params.Set("Fields", itemFields)
+
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/Shows/NextUp", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// LatestItems returns the items most recently added to the server, newest
+// first, optionally only below parentID. Episodes and tracks are returned
+// individually rather than grouped by series or album.
+func (c *Client) LatestItems(ctx context.Context, parentID string, types []string, limit int) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+	if parentID != "" {
+		params.Set("ParentId", parentID)
+	}
+	if len(types) > 0 {
+		params.Set("IncludeItemTypes", strings.Join(types, ","))
+	}
+	if limit > 0 {
+		params.Set("Limit", fmt.Sprintf("%d", limit))
+	}
+	params.Set("GroupItems", "false")
+	params.Set("Fields", itemFields)
+	params.Set("EnableUserData", "true")
+
+	var resp []Item
+	if err := c.getJSON(ctx, "/Items/Latest", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ResumeItems returns the user's partially watched movies and episodes.
+func (c *Client) ResumeItems(ctx context.Context) ([]Item, error) {
+	params := url.Values{}
+	if c.userID != "" {
+		params.Set("UserId", c.userID)
+	}
+	params.Set("MediaTypes", "Video")
+	params.Set("Fields", itemFields)
+
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/UserItems/Resume", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
 func (c *Client) SeriesEpisodes(ctx context.Context, seriesID string) ([]Item, error) {
 	params := url.Values{}
 	if c.userID != "" {
@@ -124,6 +385,8 @@ func (c *Client) SeriesEpisodes(ctx context.Context, seriesID string) ([]Item, e
 	params.Set("Recursive", "true")
 	params.Set("IncludeItemTypes", "Episode")
 	params.Set("ParentId", seriesID)
+	params.Set("Fields", itemFields)
+	params.Set("EnableUserData", "true")
 
 	var resp ItemsResponse
 	if err := c.getJSON(ctx, "/Items", params, &resp); err != nil {
@@ -133,8 +396,71 @@ func (c *Client) SeriesEpisodes(ctx context.Context, seriesID string) ([]Item, e
 }
 
 func (c *Client) OpenDownload(ctx context.Context, itemID string, offset int64) (*http.Response, error) {
-	endpoint := fmt.Sprintf("/Items/%s/Download", itemID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	return c.openStream(ctx, fmt.Sprintf("/Items/%s/Download", itemID), nil, offset)
+}
+
+func (c *Client) OpenImage(ctx context.Context, itemID, imageType string) (*http.Response, error) {
+	return c.openStream(ctx, fmt.Sprintf("/Items/%s/Images/%s", itemID, imageType), nil, 0)
+}
+
+func (c *Client) OpenMediaSource(ctx context.Context, itemID, mediaSourceID string, offset int64) (*http.Response, error) {
+	params := url.Values{}
+	params.Set("static", "true")
+	params.Set("mediaSourceId", mediaSourceID)
+	return c.openStream(ctx, fmt.Sprintf("/Videos/%s/stream", itemID), params, offset)
+}
+
+func (c *Client) OpenTranscode(ctx context.Context, itemID string, opts TranscodeOptions) (*http.Response, error) {
+	params := url.Values{}
+	params.Set("static", "false")
+	if c.deviceID != "" {
+		params.Set("deviceId", c.deviceID)
+	}
+	if opts.MediaSourceID != "" {
+		params.Set("mediaSourceId", opts.MediaSourceID)
+	}
+	if opts.AudioCodec != "" {
+		params.Set("audioCodec", opts.AudioCodec)
+	}
+	container := opts.Container
+	if container == "" {
+		container = "mp4"
+	}
+	if opts.Audio {
+		if opts.MaxBitrate > 0 {
+			params.Set("audioBitRate", fmt.Sprintf("%d", opts.MaxBitrate))
+		}
+		return c.openStream(ctx, fmt.Sprintf("/Audio/%s/stream.%s", itemID, container), params, 0)
+	}
+	if opts.VideoCodec != "" {
+		params.Set("videoCodec", opts.VideoCodec)
+	}
+	if opts.MaxBitrate > 0 {
+		params.Set("videoBitRate", fmt.Sprintf("%d", opts.MaxBitrate))
+	}
+	if opts.MaxWidth > 0 {
+		params.Set("maxWidth", fmt.Sprintf("%d", opts.MaxWidth))
+	}
+	if opts.MaxHeight > 0 {
+		params.Set("maxHeight", fmt.Sprintf("%d", opts.MaxHeight))
+	}
+	if opts.AudioStreamIndex != nil {
+		params.Set("audioStreamIndex", fmt.Sprintf("%d", *opts.AudioStreamIndex))
+	}
+	return c.openStream(ctx, fmt.Sprintf("/Videos/%s/stream.%s", itemID, container), params, 0)
+}
+
+func (c *Client) OpenSubtitle(ctx context.Context, itemID, mediaSourceID string, streamIndex int, format string) (*http.Response, error) {
+	endpoint := fmt.Sprintf("/Videos/%s/%s/Subtitles/%d/Stream.%s", itemID, mediaSourceID, streamIndex, format)
+	return c.openStream(ctx, endpoint, nil, 0)
+}
+
+func (c *Client) openStream(ctx context.Context, endpoint string, params url.Values, offset int64) (*http.Response, error) {
+	target := c.baseURL + endpoint
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ItemQuery describes a request to the /Items endpoint.
+type ItemQuery struct {
+	IDs            []string
+	ParentID       string
+	Types          []string
+	Recursive      bool
+	SearchTerm     string
+	ArtistIDs      []string
+	AlbumArtistIDs []string
+	Genres         []string
+	Years          []int
+	MinRating      float64
+	IsPlayed       *bool
+	IsFavorite     *bool
+	Tags           []string
+	MinDateCreated time.Time
+	SortBy         []string
+	SortOrder      string
+	Fields         []string
+	UserData       bool
+	StartIndex     int
+	Limit          int
+}
+
+func (q ItemQuery) params(userID string) url.Values {
+	params := url.Values{}
+	if userID != "" {
+		params.Set("UserId", userID)
+	}
+	if len(q.IDs) > 0 {
+		params.Set("Ids", strings.Join(q.IDs, ","))
+	}
+	if q.ParentID != "" {
+		params.Set("ParentId", q.ParentID)
+	}
+	if len(q.Types) > 0 {
+		params.Set("IncludeItemTypes", strings.Join(q.Types, ","))
+	}
+	if q.Recursive {
+		params.Set("Recursive", "true")
+	}
+	if q.SearchTerm != "" {
+		params.Set("SearchTerm", q.SearchTerm)
+	}
+	if len(q.ArtistIDs) > 0 {
+		params.Set("ArtistIds", strings.Join(q.ArtistIDs, ","))
+	}
+	if len(q.AlbumArtistIDs) > 0 {
+		params.Set("AlbumArtistIds", strings.Join(q.AlbumArtistIDs, ","))
+	}
+	if len(q.Genres) > 0 {
+		params.Set("Genres", strings.Join(q.Genres, "|"))
+	}
+	if len(q.Years) > 0 {
+		years := make([]string, len(q.Years))
+		for i, y := range q.Years {
+			years[i] = fmt.Sprintf("%d", y)
+		}
+		params.Set("Years", strings.Join(years, ","))
+	}
+	if q.MinRating > 0 {
+		params.Set("MinCommunityRating", fmt.Sprintf("%g", q.MinRating))
+	}
+	if q.IsPlayed != nil {
+		params.Set("IsPlayed", fmt.Sprintf("%t", *q.IsPlayed))
+	}
+	if q.IsFavorite != nil {
+		params.Set("IsFavorite", fmt.Sprintf("%t", *q.IsFavorite))
+	}
+	if len(q.Tags) > 0 {
+		params.Set("Tags", strings.Join(q.Tags, "|"))
+	}
+	if !q.MinDateCreated.IsZero() {
+		params.Set("MinDateCreated", q.MinDateCreated.UTC().Format(time.RFC3339))
+	}
+	if len(q.SortBy) > 0 {
+		params.Set("SortBy", strings.Join(q.SortBy, ","))
+	}
+	if q.SortOrder != "" {
+		params.Set("SortOrder", q.SortOrder)
+	}
+	fields := q.Fields
+	if len(fields) == 0 {
+		fields = strings.Split(itemFields, ",")
+	}
+	params.Set("Fields", strings.Join(fields, ","))
+	if q.UserData {
+		params.Set("EnableUserData", "true")
+	}
+	if q.StartIndex > 0 {
+		params.Set("StartIndex", fmt.Sprintf("%d", q.StartIndex))
+	}
+	if q.Limit > 0 {
+		params.Set("Limit", fmt.Sprintf("%d", q.Limit))
+	}
+	return params
+}
+
+func (c *Client) QueryItems(ctx context.Context, q ItemQuery) (*ItemsResponse, error) {
+	var resp ItemsResponse
+	if err := c.getJSON(ctx, "/Items", q.params(c.userID), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+const defaultPageSize = 200
+
+// QueryAllItems pages through every result of q.
+func (c *Client) QueryAllItems(ctx context.Context, q ItemQuery) ([]Item, error) {
+	if q.Limit <= 0 {
+		q.Limit = defaultPageSize
+	}
+	var out []Item
+	for {
+		resp, err := c.QueryItems(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, resp.Items...)
+		q.StartIndex += len(resp.Items)
+		if len(resp.Items) == 0 || q.StartIndex >= resp.TotalRecordCount {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestItemQueryParams(t *testing.T) {
+	played := false
+	favorite := true
+	params := ItemQuery{
+		IDs:            []string{"a", "b"},
+		ParentID:       "lib",
+		Types:          []string{"Movie", "Episode"},
+		Recursive:      true,
+		Genres:         []string{"Drama", "Sci-Fi"},
+		Years:          []int{2010, 2011},
+		MinRating:      7.5,
+		IsPlayed:       &played,
+		IsFavorite:     &favorite,
+		Tags:           []string{"offline", "kids"},
+		UserData:       true,
+		MinDateCreated: time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+	}.params("user-1")
+
+	want := map[string]string{
+		"UserId":             "user-1",
+		"Ids":                "a,b",
+		"ParentId":           "lib",
+		"IncludeItemTypes":   "Movie,Episode",
+		"Recursive":          "true",
+		"Genres":             "Drama|Sci-Fi",
+		"Years":              "2010,2011",
+		"MinCommunityRating": "7.5",
+		"IsPlayed":           "false",
+		"IsFavorite":         "true",
+		"Tags":               "offline|kids",
+		"MinDateCreated":     "2024-06-01T10:00:00Z",
+		"Fields":             itemFields,
+		"EnableUserData":     "true",
+	}
+	for key, value := range want {
+		if got := params.Get(key); got != value {
+			t.Fatalf("param %s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestQueryAllItemsPages(t *testing.T) {
+	const total = 5
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := strconv.Atoi(r.URL.Query().Get("StartIndex"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("Limit"))
+		var resp ItemsResponse
+		resp.TotalRecordCount = total
+		for i := start; i < start+limit && i < total; i++ {
+			resp.Items = append(resp.Items, Item{Id: fmt.Sprintf("item-%d", i)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", "user", "device", "", 5*time.Second)
+	items, err := client.QueryAllItems(context.Background(), ItemQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryAllItems: %v", err)
+	}
+	if len(items) != total || items[total-1].Id != "item-4" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestSeriesEpisodesUserData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("EnableUserData") != "true" {
+			t.Errorf("expected EnableUserData=true, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"Items":[
+			{"Id":"e1","UserData":{"Played":true,"IsFavorite":true}},
+			{"Id":"e2","UserData":{"Played":false,"PlaybackPositionTicks":1200}},
+			{"Id":"e3"}
+		],"TotalRecordCount":3}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", "user", "device", "", 5*time.Second)
+	items, err := client.SeriesEpisodes(context.Background(), "series")
+	if err != nil {
+		t.Fatalf("SeriesEpisodes: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 episodes, got %d", len(items))
+	}
+	if !items[0].UserData.Played || !items[0].UserData.IsFavorite || items[0].UserData.InProgress() {
+		t.Fatalf("unexpected user data for e1: %+v", items[0].UserData)
+	}
+	if !items[1].UserData.InProgress() {
+		t.Fatalf("expected e2 to be in progress")
+	}
+	if items[2].UserData.InProgress() {
+		t.Fatalf("missing user data must not count as in progress")
+	}
+}
+
+func TestLatestItems(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/Items/Latest" || q.Get("GroupItems") != "false" || q.Get("ParentId") != "lib" ||
+			q.Get("IncludeItemTypes") != "Movie,Episode" || q.Get("Limit") != "50" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`[{"Id":"m1","Type":"Movie","DateCreated":"2024-06-01T10:00:00.0000000Z"},{"Id":"e1","Type":"Episode"}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", "user", "device", "", 5*time.Second)
+	items, err := client.LatestItems(context.Background(), "lib", []string{"Movie", "Episode"}, 50)
+	if err != nil {
+		t.Fatalf("LatestItems: %v", err)
+	}
+	if len(items) != 2 || items[0].DateCreated == "" || items[1].Id != "e1" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+}
+
+func TestVirtualFolders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Library/VirtualFolders" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`[{"Name":"Movies","ItemId":"lib","CollectionType":"movies","Locations":["/media/movies","/mnt/extra/movies"]}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", "user", "device", "", 5*time.Second)
+	folders, err := client.VirtualFolders(context.Background())
+	if err != nil {
+		t.Fatalf("VirtualFolders: %v", err)
+	}
+	if len(folders) != 1 || folders[0].ItemId != "lib" || len(folders[0].Locations) != 2 {
+		t.Fatalf("unexpected folders: %+v", folders)
+	}
+}
+
+func TestOpenTranscodeEndpoint(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Path+" videoBitRate="+r.URL.Query().Get("videoBitRate")+" audioBitRate="+r.URL.Query().Get("audioBitRate"))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token", "user", "device", "", 5*time.Second)
+	for _, audio := range []bool{false, true} {
+		resp, err := client.OpenTranscode(context.Background(), "item", TranscodeOptions{Audio: audio, Container: "mp4", MaxBitrate: 320000})
+		if err != nil {
+			t.Fatalf("OpenTranscode: %v", err)
+		}
+		resp.Body.Close()
+	}
+	want := []string{
+		"/Videos/item/stream.mp4 videoBitRate=320000 audioBitRate=",
+		"/Audio/item/stream.mp4 videoBitRate= audioBitRate=320000",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestSocketURL(t *testing.T) {
+	client := NewClient("https://media.example.com/jellyfin", "tok", "user", "dev", "", 0)
+	got, err := client.SocketURL()
+	if err != nil {
+		t.Fatalf("SocketURL: %v", err)
+	}
+	if want := "wss://media.example.com/jellyfin/socket?api_key=tok&deviceId=dev"; got != want {
+		t.Fatalf("SocketURL = %s, want %s", got, want)
+	}
+}
@@ -1,5 +1,7 @@
 package api
 
+import "strings"
+
 type AuthResponse struct {
 	AccessToken string `json:"AccessToken"`
 	User        User   `json:"User"`
@@ -15,13 +17,211 @@ type ItemsResponse struct {
 	TotalRecordCount int    `json:"TotalRecordCount"`
 }
 
+type ThemeMediaResult struct {
+	Items   []Item `json:"Items"`
+	OwnerId string `json:"OwnerId"`
+}
+
+type AllThemeMediaResult struct {
+	ThemeVideosResult ThemeMediaResult `json:"ThemeVideosResult"`
+	ThemeSongsResult  ThemeMediaResult `json:"ThemeSongsResult"`
+}
+
 type Item struct {
-	Id                string `json:"Id"`
-	Name              string `json:"Name"`
+	Id                      string `json:"Id"`
+	Name                    string `json:"Name"`
+	Type                    string `json:"Type"`
+	SeriesId                string `json:"SeriesId"`
+	SeriesName              string `json:"SeriesName"`
+	IndexNumber             int    `json:"IndexNumber"`
+	IndexNumberEnd          int    `json:"IndexNumberEnd"`
+	ParentIndexNumber       int    `json:"ParentIndexNumber"`
+	AirsBeforeSeasonNumber  int    `json:"AirsBeforeSeasonNumber"`
+	AirsBeforeEpisodeNumber int    `json:"AirsBeforeEpisodeNumber"`
+	ProductionYear          int    `json:"ProductionYear"`
+	PremiereDate            string `json:"PremiereDate"`
+	DateCreated             string `json:"DateCreated,omitempty"`
+	Overview                string `json:"Overview,omitempty"`
+	CollectionType          string `json:"CollectionType,omitempty"`
+	Path                    string `json:"Path"`
+	LocationType            string `json:"LocationType,omitempty"`
+	RunTimeTicks            int64  `json:"RunTimeTicks,omitempty"`
+	PartCount               int    `json:"PartCount,omitempty"`
+	ExtraType               string `json:"ExtraType,omitempty"`
+
+	ChannelName   string `json:"ChannelName,omitempty"`
+	EpisodeTitle  string `json:"EpisodeTitle,omitempty"`
+	StartDate     string `json:"StartDate,omitempty"`
+	SeriesTimerId string `json:"SeriesTimerId,omitempty"`
+
+	Album       string   `json:"Album,omitempty"`
+	AlbumId     string   `json:"AlbumId,omitempty"`
+	AlbumArtist string   `json:"AlbumArtist,omitempty"`
+	Artists     []string `json:"Artists,omitempty"`
+	People      []Person `json:"People,omitempty"`
+
+	MediaSources []MediaSource `json:"MediaSources,omitempty"`
+	MediaStreams []MediaStream `json:"MediaStreams,omitempty"`
+
+	UserData *UserData `json:"UserData,omitempty"`
+}
+
+// VirtualFolder is a library as configured on the server. Locations are the
+// library's physical folders.
+type VirtualFolder struct {
+	Name           string   `json:"Name"`
+	ItemId         string   `json:"ItemId"`
+	CollectionType string   `json:"CollectionType,omitempty"`
+	Locations      []string `json:"Locations"`
+}
+
+type UserData struct {
+	Played                bool  `json:"Played"`
+	IsFavorite            bool  `json:"IsFavorite"`
+	PlaybackPositionTicks int64 `json:"PlaybackPositionTicks"`
+	PlayCount             int   `json:"PlayCount"`
+}
+
+// InProgress reports whether playback was started but not finished.
+func (u *UserData) InProgress() bool {
+	return u != nil && !u.Played && u.PlaybackPositionTicks > 0
+}
+
+type Person struct {
+	Name string `json:"Name"`
+	Type string `json:"Type,omitempty"`
+}
+
+type SeriesTimer struct {
+	Id          string `json:"Id"`
+	Name        string `json:"Name"`
+	ChannelName string `json:"ChannelName,omitempty"`
+}
+
+type SeriesTimersResponse struct {
+	Items []SeriesTimer `json:"Items"`
+}
+
+type MediaSource struct {
+	Id           string        `json:"Id"`
+	Name         string        `json:"Name"`
+	Path         string        `json:"Path"`
+	Container    string        `json:"Container"`
+	Size         int64         `json:"Size"`
+	Bitrate      int64         `json:"Bitrate"`
+	ETag         string        `json:"ETag,omitempty"`
+	MediaStreams []MediaStream `json:"MediaStreams,omitempty"`
+}
+
+type MediaStream struct {
 	Type              string `json:"Type"`
-	SeriesName        string `json:"SeriesName"`
-	IndexNumber       int    `json:"IndexNumber"`
-	ParentIndexNumber int    `json:"ParentIndexNumber"`
-	ProductionYear    int    `json:"ProductionYear"`
-	Path              string `json:"Path"`
+	Index             int    `json:"Index"`
+	Codec             string `json:"Codec"`
+	Language          string `json:"Language"`
+	DisplayTitle      string `json:"DisplayTitle"`
+	Width             int    `json:"Width"`
+	Height            int    `json:"Height"`
+	VideoRangeType    string `json:"VideoRangeType"`
+	IsForced          bool   `json:"IsForced"`
+	IsHearingImpaired bool   `json:"IsHearingImpaired"`
+	IsExternal        bool   `json:"IsExternal"`
+}
+
+func (s MediaSource) stream(streamType string) *MediaStream {
+	for i := range s.MediaStreams {
+		if s.MediaStreams[i].Type == streamType {
+			return &s.MediaStreams[i]
+		}
+	}
+	return nil
+}
+
+func (s MediaSource) VideoCodec() string {
+	if v := s.stream("Video"); v != nil {
+		return strings.ToLower(v.Codec)
+	}
+	return ""
+}
+
+func (s MediaSource) AudioCodec() string {
+	if a := s.stream("Audio"); a != nil {
+		return strings.ToLower(a.Codec)
+	}
+	return ""
+}
+
+// Resolution returns a label such as "2160p" or "1080p" for the first video
+// stream. Width is considered so cropped widescreen encodes are not
+// under-reported.
+func (s MediaSource) Resolution() string {
+	v := s.stream("Video")
+	if v == nil || (v.Width == 0 && v.Height == 0) {
+		return ""
+	}
+	switch {
+	case v.Width >= 3200 || v.Height >= 2000:
+		return "2160p"
+	case v.Width >= 1800 || v.Height >= 1000:
+		return "1080p"
+	case v.Width >= 1200 || v.Height >= 700:
+		return "720p"
+	case v.Height >= 560:
+		return "576p"
+	default:
+		return "480p"
+	}
+}
+
+// HDRType returns the normalized video range: sdr, hdr10, hdr10+, dv or hlg.
+func (s MediaSource) HDRType() string {
+	v := s.stream("Video")
+	if v == nil {
+		return ""
+	}
+	rangeType := strings.ToLower(v.VideoRangeType)
+	switch {
+	case rangeType == "" || rangeType == "unknown":
+		return ""
+	case strings.HasPrefix(rangeType, "dovi"):
+		return "dv"
+	case rangeType == "hdr10plus":
+		return "hdr10+"
+	default:
+		return rangeType
+	}
+}
+
+// PrimarySource returns the media source used for downloads and stream
+// lookups, falling back to a synthetic source built from the item itself.
+func (i Item) PrimarySource() MediaSource {
+	if len(i.MediaSources) > 0 {
+		src := i.MediaSources[0]
+		if len(src.MediaStreams) == 0 {
+			src.MediaStreams = i.MediaStreams
+		}
+		return src
+	}
+	return MediaSource{Id: i.Id, Path: i.Path, MediaStreams: i.MediaStreams}
+}
+
+type TranscodeOptions struct {
+	// Audio selects the audio stream endpoint for music and audiobook items.
+	Audio            bool
+	MediaSourceID    string
+	Container        string
+	VideoCodec       string
+	AudioCodec       string
+	MaxBitrate       int64
+	MaxWidth         int
+	MaxHeight        int
+	AudioStreamIndex *int
+}
+
+type LyricLine struct {
+	Text  string `json:"Text"`
+	Start *int64 `json:"Start"`
+}
+
+type LyricsResponse struct {
+	Lyrics []LyricLine `json:"Lyrics"`
 }
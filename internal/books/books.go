@@ -0,0 +1,38 @@
+package books
+
+import (
+	"fmt"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+// Author returns the book's author, falling back to the album artist and
+// artists that audiobook files are tagged with.
+func Author(item api.Item) string {
+	for _, p := range item.People {
+		if p.Type == "Author" && p.Name != "" {
+			return p.Name
+		}
+	}
+	if item.AlbumArtist != "" {
+		return item.AlbumArtist
+	}
+	if len(item.Artists) > 0 {
+		return item.Artists[0]
+	}
+	return "Unknown Author"
+}
+
+// BookFileName returns the title, prefixed with the position in its series
+// ("02 - Title") when the book belongs to one.
+func BookFileName(item api.Item) string {
+	if item.SeriesName != "" && item.IndexNumber > 0 {
+		return fmt.Sprintf("%02d - %s", item.IndexNumber, item.Name)
+	}
+	return item.Name
+}
+
+// ChapterFileName returns "NN - Title" for the n-th file of an audiobook.
+func ChapterFileName(n int, title string) string {
+	return fmt.Sprintf("%02d - %s", n, title)
+}
@@ -0,0 +1,36 @@
+package books
+
+import (
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+func TestAuthor(t *testing.T) {
+	cases := []struct {
+		item api.Item
+		want string
+	}{
+		{api.Item{People: []api.Person{{Name: "Editor", Type: "Editor"}, {Name: "Le Guin", Type: "Author"}}}, "Le Guin"},
+		{api.Item{AlbumArtist: "Narrator", Artists: []string{"Other"}}, "Narrator"},
+		{api.Item{Artists: []string{"Pratchett"}}, "Pratchett"},
+		{api.Item{}, "Unknown Author"},
+	}
+	for _, tc := range cases {
+		if got := Author(tc.item); got != tc.want {
+			t.Fatalf("Author(%+v) = %q, want %q", tc.item, got, tc.want)
+		}
+	}
+}
+
+func TestBookFileName(t *testing.T) {
+	if got := BookFileName(api.Item{Name: "Mort", SeriesName: "Discworld", IndexNumber: 4}); got != "04 - Mort" {
+		t.Fatalf("BookFileName series = %q", got)
+	}
+	if got := BookFileName(api.Item{Name: "Mort", IndexNumber: 4}); got != "Mort" {
+		t.Fatalf("BookFileName standalone = %q", got)
+	}
+	if got := ChapterFileName(3, "Chapter 3"); got != "03 - Chapter 3" {
+		t.Fatalf("ChapterFileName = %q", got)
+	}
+}
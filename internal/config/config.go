@@ -21,6 +21,126 @@ type Config struct {
 	DeviceName   string `json:"device_name"`
 	DefaultRate  string `json:"default_rate"`
 	LastUsername string `json:"last_username"`
+
+	Layout          string `json:"layout,omitempty"`
+	SpecialsFolder  string `json:"specials_folder,omitempty"`
+	EpisodeTemplate string `json:"episode_template,omitempty"`
+	MovieTemplate   string `json:"movie_template,omitempty"`
+
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+	Sync      *SyncConfig      `json:"sync,omitempty"`
+}
+
+// Retention configures when downloaded files are deleted by
+// `downloads prune`. Zero values disable a rule.
+type Retention struct {
+	DeleteWatched *bool `json:"delete_watched,omitempty"`
+	KeepLatest    int   `json:"keep_latest,omitempty"`
+	MaxAgeDays    int   `json:"max_age_days,omitempty"`
+}
+
+// RetentionOverride replaces the default rules for one series. Only the
+// fields that are set apply, so an explicit 0 turns a default rule off.
+type RetentionOverride struct {
+	DeleteWatched *bool `json:"delete_watched,omitempty"`
+	KeepLatest    *int  `json:"keep_latest,omitempty"`
+	MaxAgeDays    *int  `json:"max_age_days,omitempty"`
+}
+
+// RetentionPolicy holds the default retention rules, per-series overrides
+// keyed by series ID or name, and a limit for the total size of all
+// downloads (e.g. "200G").
+type RetentionPolicy struct {
+	Retention
+	MaxTotalSize string                       `json:"max_total_size,omitempty"`
+	Series       map[string]RetentionOverride `json:"series,omitempty"`
+}
+
+// ForSeries returns the rules for a series: the defaults with every field
+// that the series override sets replaced.
+func (p *RetentionPolicy) ForSeries(seriesID, seriesName string) Retention {
+	if p == nil {
+		return Retention{}
+	}
+	rules := p.Retention
+	override, ok := p.Series[seriesID]
+	if !ok && seriesName != "" {
+		override, ok = p.Series[seriesName]
+	}
+	if !ok {
+		return rules
+	}
+	if override.DeleteWatched != nil {
+		rules.DeleteWatched = override.DeleteWatched
+	}
+	if override.KeepLatest != nil {
+		rules.KeepLatest = *override.KeepLatest
+	}
+	if override.MaxAgeDays != nil {
+		rules.MaxAgeDays = *override.MaxAgeDays
+	}
+	return rules
+}
+
+// SyncConfig declares what `sync` mirrors to this device. It is read from
+// the "sync" key of the config or from a file passed with --config.
+type SyncConfig struct {
+	Output string     `json:"output,omitempty"`
+	Rules  []SyncRule `json:"rules"`
+}
+
+// SyncRule selects items of a series, movie, collection, playlist or
+// library, or the items the user marked as favorite or with Tag (optionally
+// below the library ID). Profile and Version set the quality of its
+// downloads; Retention drops items from the selection (delete_watched,
+// keep_latest, max_age_days by date added on the server). Favorites and tag
+// rules leave local copies of items that stop matching on disk unless
+// RemoveUnmatched is set.
+type SyncRule struct {
+	Name      string    `json:"name,omitempty"`
+	Type      string    `json:"type"`
+	ID        string    `json:"id,omitempty"`
+	Tag       string    `json:"tag,omitempty"`
+	Episodes  string    `json:"episodes,omitempty"`
+	Types     []string  `json:"types,omitempty"`
+	Unplayed  bool      `json:"unplayed,omitempty"`
+	Profile   string    `json:"profile,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	Retention Retention `json:"retention,omitempty"`
+
+	RemoveUnmatched bool `json:"remove_unmatched,omitempty"`
+}
+
+// KeepsFiles reports whether downloads of items the rule no longer selects
+// stay on disk. Un-favoriting or un-tagging an item only removes its copy
+// when the rule opts in with RemoveUnmatched.
+func (r SyncRule) KeepsFiles() bool {
+	return (r.Type == "favorites" || r.Type == "tag") && !r.RemoveUnmatched
+}
+
+// Key identifies the rule in the store: its name, or "<type>:<tag or id>".
+func (r SyncRule) Key() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Tag != "":
+		return r.Type + ":" + r.Tag
+	case r.ID != "":
+		return r.Type + ":" + r.ID
+	}
+	return r.Type
+}
+
+func LoadSync(path string) (*SyncConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sync file: %w", err)
+	}
+	var sc SyncConfig
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("parsing sync file: %w", err)
+	}
+	return &sc, nil
 }
 
 func ResolveStoreDir(override string) (string, error) {
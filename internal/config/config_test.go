@@ -35,3 +35,72 @@ func TestNormalizeServerURL(t *testing.T) {
 		}
 	}
 }
+
+func TestRetentionForSeries(t *testing.T) {
+	no := false
+	yes := true
+	three, seven, off := 3, 7, 0
+	policy := &RetentionPolicy{
+		Retention: Retention{DeleteWatched: &yes, KeepLatest: 10, MaxAgeDays: 30},
+		Series: map[string]RetentionOverride{
+			"series-1":  {DeleteWatched: &no, KeepLatest: &three},
+			"Kids Show": {MaxAgeDays: &seven},
+			"Keep All":  {KeepLatest: &off, MaxAgeDays: &off},
+		},
+	}
+
+	got := policy.ForSeries("series-1", "Drama")
+	if *got.DeleteWatched || got.KeepLatest != 3 || got.MaxAgeDays != 30 {
+		t.Fatalf("override by id = %+v", got)
+	}
+	got = policy.ForSeries("series-2", "Kids Show")
+	if !*got.DeleteWatched || got.MaxAgeDays != 7 {
+		t.Fatalf("override by name = %+v", got)
+	}
+	got = policy.ForSeries("series-3", "Keep All")
+	if !*got.DeleteWatched || got.KeepLatest != 0 || got.MaxAgeDays != 0 {
+		t.Fatalf("override with 0 = %+v", got)
+	}
+	got = policy.ForSeries("", "")
+	if !*got.DeleteWatched || got.KeepLatest != 10 || got.MaxAgeDays != 30 {
+		t.Fatalf("defaults = %+v", got)
+	}
+	var empty *RetentionPolicy
+	if got := empty.ForSeries("x", ""); got.DeleteWatched != nil {
+		t.Fatalf("nil policy = %+v", got)
+	}
+}
+
+func TestSyncRuleKey(t *testing.T) {
+	cases := []struct {
+		rule SyncRule
+		want string
+	}{
+		{SyncRule{Name: "kids", Type: "tag", Tag: "offline"}, "kids"},
+		{SyncRule{Type: "tag", Tag: "offline", ID: "lib"}, "tag:offline"},
+		{SyncRule{Type: "series", ID: "abc"}, "series:abc"},
+		{SyncRule{Type: "favorites"}, "favorites"},
+	}
+	for _, tc := range cases {
+		if got := tc.rule.Key(); got != tc.want {
+			t.Fatalf("Key(%+v) = %q, want %q", tc.rule, got, tc.want)
+		}
+	}
+}
+
+func TestSyncRuleKeepsFiles(t *testing.T) {
+	cases := []struct {
+		rule SyncRule
+		want bool
+	}{
+		{SyncRule{Type: "favorites"}, true},
+		{SyncRule{Type: "tag", Tag: "offline"}, true},
+		{SyncRule{Type: "tag", Tag: "offline", RemoveUnmatched: true}, false},
+		{SyncRule{Type: "series", ID: "abc"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.rule.KeepsFiles(); got != tc.want {
+			t.Fatalf("KeepsFiles(%+v) = %v, want %v", tc.rule, got, tc.want)
+		}
+	}
+}
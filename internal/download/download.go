@@ -17,7 +17,11 @@ const (
 	defaultChunkSize = 256 * 1024
 )
 
-var filenameCleaner = regexp.MustCompile(`[^a-zA-Z0-9._\- ]+`)
+var (
+	filenameCleaner    = regexp.MustCompile(`[^a-zA-Z0-9._\- ]+`)
+	pathSegmentCleaner = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+	windowsDrive       = regexp.MustCompile(`^[a-zA-Z]:$`)
+)
 
 func SanitizeFileName(name string) string {
 	clean := strings.TrimSpace(name)
@@ -102,6 +106,27 @@ func ParseRateLimit(rateStr string) (*rate.Limiter, error) {
 	return rate.NewLimiter(limit, int(bytesPerSec)), nil
 }
 
+// ParseAge parses a positive duration such as "90m", "24h", "7d" or "2w".
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	invalid := fmt.Errorf("invalid duration %q (e.g. 24h, 7d, 2w)", value)
+	if n := len(value); n > 1 && (value[n-1] == 'd' || value[n-1] == 'w') {
+		days, err := strconv.ParseFloat(value[:n-1], 64)
+		if err != nil || days <= 0 {
+			return 0, invalid
+		}
+		if value[n-1] == 'w' {
+			days *= 7
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
+
 func splitNumberUnit(input string) (float64, string, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -138,3 +163,59 @@ func DefaultDownloadPath(baseDir, name, ext string) string {
 	}
 	return filepath.Join(baseDir, fileName+ext)
 }
+
+func SanitizePathSegment(name string) string {
+	clean := pathSegmentCleaner.ReplaceAllString(strings.TrimSpace(name), "_")
+	clean = strings.TrimRight(clean, ". ")
+	if clean == "" {
+		return "_"
+	}
+	return clean
+}
+
+// MirrorPath maps serverPath below libraryRoot into baseDir. Server paths may
+// use Windows or Unix separators.
+func MirrorPath(baseDir, libraryRoot, serverPath string) (string, error) {
+	rootSegs, rootWindows := splitServerPath(libraryRoot)
+	segs, windows := splitServerPath(serverPath)
+	fold := rootWindows || windows
+
+	if len(segs) <= len(rootSegs) {
+		return "", fmt.Errorf("path %q is not inside library root %q", serverPath, libraryRoot)
+	}
+	for i, seg := range rootSegs {
+		if seg == segs[i] || (fold && strings.EqualFold(seg, segs[i])) {
+			continue
+		}
+		return "", fmt.Errorf("path %q is not inside library root %q", serverPath, libraryRoot)
+	}
+
+	parts := []string{baseDir}
+	for _, seg := range segs[len(rootSegs):] {
+		parts = append(parts, SanitizePathSegment(seg))
+	}
+	return filepath.Join(parts...), nil
+}
+
+// ServerBaseName returns the last element of a server path, which may use
+// Windows or Unix separators.
+func ServerBaseName(serverPath string) string {
+	segs, _ := splitServerPath(serverPath)
+	if len(segs) == 0 {
+		return ""
+	}
+	return segs[len(segs)-1]
+}
+
+func splitServerPath(p string) ([]string, bool) {
+	p = strings.ReplaceAll(p, "\\", "/")
+	var segs []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" || seg == "." {
+			continue
+		}
+		segs = append(segs, seg)
+	}
+	windows := strings.HasPrefix(p, "//") || (len(segs) > 0 && windowsDrive.MatchString(segs[0]))
+	return segs, windows
+}
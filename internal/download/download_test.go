@@ -1,7 +1,9 @@
 package download
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSanitizeFileName(t *testing.T) {
@@ -51,3 +53,100 @@ func TestParseRateLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{
+		"24h":  24 * time.Hour,
+		"7d":   7 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"90m":  90 * time.Minute,
+		"1.5d": 36 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := ParseAge(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "0d", "-1h", "7x", "abc"} {
+		if _, err := ParseAge(in); err == nil {
+			t.Fatalf("ParseAge(%q): expected error", in)
+		}
+	}
+}
+
+func TestSanitizePathSegment(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{in: "Alien (1979) [Director's Cut]", want: "Alien (1979) [Director's Cut]"},
+		{in: "What? Why: No", want: "What_ Why_ No"},
+		{in: "Trailing dots...", want: "Trailing dots"},
+		{in: "..", want: "_"},
+	}
+
+	for _, tc := range cases {
+		if got := SanitizePathSegment(tc.in); got != tc.want {
+			t.Fatalf("SanitizePathSegment(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestMirrorPath(t *testing.T) {
+	cases := []struct {
+		root     string
+		path     string
+		want     string
+		expectOK bool
+	}{
+		{
+			root:     "/media/movies",
+			path:     "/media/movies/Collections/Alien/Alien (1979)/Alien (1979).mkv",
+			want:     filepath.Join("out", "Collections", "Alien", "Alien (1979)", "Alien (1979).mkv"),
+			expectOK: true,
+		},
+		{
+			root:     `D:\Media\TV`,
+			path:     `d:\media\tv\Show\Season 1\Show S01E01.mkv`,
+			want:     filepath.Join("out", "Show", "Season 1", "Show S01E01.mkv"),
+			expectOK: true,
+		},
+		{
+			root:     `\\nas\share\Movies`,
+			path:     `//nas/share/Movies/Heat: Remastered/Heat.mkv`,
+			want:     filepath.Join("out", "Heat_ Remastered", "Heat.mkv"),
+			expectOK: true,
+		},
+		{root: "/media/movies", path: "/media/tv/Show/ep.mkv"},
+		{root: "/media/movies", path: "/media/movies"},
+	}
+
+	for _, tc := range cases {
+		got, err := MirrorPath("out", tc.root, tc.path)
+		if tc.expectOK {
+			if err != nil {
+				t.Fatalf("MirrorPath(%q, %q) unexpected error: %v", tc.root, tc.path, err)
+			}
+			if got != tc.want {
+				t.Fatalf("MirrorPath(%q, %q) = %q, want %q", tc.root, tc.path, got, tc.want)
+			}
+		} else if err == nil {
+			t.Fatalf("MirrorPath(%q, %q) expected error", tc.root, tc.path)
+		}
+	}
+}
+
+func TestServerBaseName(t *testing.T) {
+	cases := map[string]string{
+		"/photos/2019/IMG_0001.JPG": "IMG_0001.JPG",
+		`D:\Photos\Holiday\`:        "Holiday",
+		"clip.mp4":                  "clip.mp4",
+		"":                          "",
+	}
+	for in, want := range cases {
+		if got := ServerBaseName(in); got != want {
+			t.Fatalf("ServerBaseName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package episodes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+// Ref is a season ("S03") or episode ("S03E07") reference. Episode is 0 for
+// a whole season.
+type Ref struct {
+	Season  int
+	Episode int
+}
+
+func (r Ref) String() string {
+	if r.Episode == 0 {
+		return fmt.Sprintf("S%02d", r.Season)
+	}
+	return fmt.Sprintf("S%02dE%02d", r.Season, r.Episode)
+}
+
+var refPattern = regexp.MustCompile(`^(?i)s(\d{1,4})(?:e(\d{1,4}))?$`)
+
+// ParseRef parses "S03" or "S03E07".
+func ParseRef(value string) (Ref, error) {
+	m := refPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return Ref{}, fmt.Errorf("invalid episode %q (expected e.g. S03 or S03E07)", value)
+	}
+	season, _ := strconv.Atoi(m[1])
+	ref := Ref{Season: season}
+	if m[2] != "" {
+		ref.Episode, _ = strconv.Atoi(m[2])
+		if ref.Episode == 0 {
+			return Ref{}, fmt.Errorf("invalid episode %q: episode numbers start at 1", value)
+		}
+	}
+	return ref, nil
+}
+
+type termKind int
+
+const (
+	termRange termKind = iota
+	termAbsolute
+	termFirst
+	termLatest
+	termAfter
+)
+
+type term struct {
+	kind     termKind
+	from, to Ref
+	min, max int
+	count    int
+}
+
+// Selector picks episodes of a series. It is built from an --episodes value:
+// a comma-separated list of terms such as "S03", "S02E01", "S01E05-S02E03",
+// absolute numbers ("25", "25-30"), "first:3", "latest:5" or "after:S04E10".
+// An episode is selected when any term matches.
+type Selector struct {
+	terms []term
+}
+
+func Parse(value string) (*Selector, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	sel := &Selector{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty term in episode selector %q", value)
+		}
+		t, err := parseTerm(part)
+		if err != nil {
+			return nil, err
+		}
+		sel.terms = append(sel.terms, t)
+	}
+	return sel, nil
+}
+
+func parseTerm(part string) (term, error) {
+	if key, arg, ok := strings.Cut(part, ":"); ok {
+		switch strings.ToLower(key) {
+		case "first", "latest":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				return term{}, fmt.Errorf("invalid count in %q (expected a number >= 1)", part)
+			}
+			if strings.ToLower(key) == "first" {
+				return term{kind: termFirst, count: n}, nil
+			}
+			return term{kind: termLatest, count: n}, nil
+		case "after":
+			ref, err := ParseRef(arg)
+			if err != nil {
+				return term{}, err
+			}
+			if ref.Episode == 0 {
+				ref.Episode = 1 << 30
+			}
+			return term{kind: termAfter, from: ref}, nil
+		}
+		return term{}, fmt.Errorf("unknown selector %q (use first:, latest: or after:)", key)
+	}
+
+	from, to, isRange := strings.Cut(part, "-")
+	if isDigits(from) {
+		min, err := strconv.Atoi(from)
+		if err != nil || min < 1 {
+			return term{}, fmt.Errorf("invalid absolute episode %q", part)
+		}
+		max := min
+		if isRange {
+			if !isDigits(to) {
+				return term{}, fmt.Errorf("invalid absolute range %q", part)
+			}
+			max, _ = strconv.Atoi(to)
+		}
+		if max < min {
+			return term{}, fmt.Errorf("invalid range %q: end is before start", part)
+		}
+		return term{kind: termAbsolute, min: min, max: max}, nil
+	}
+
+	start, err := ParseRef(from)
+	if err != nil {
+		return term{}, err
+	}
+	end := start
+	if isRange {
+		if end, err = ParseRef(to); err != nil {
+			return term{}, err
+		}
+		if (start.Episode == 0) != (end.Episode == 0) {
+			return term{}, fmt.Errorf("invalid range %q: use S01-S03 or S01E05-S02E03", part)
+		}
+		if compare(end.Season, end.Episode, start) < 0 {
+			return term{}, fmt.Errorf("invalid range %q: end is before start", part)
+		}
+	}
+	if end.Episode == 0 {
+		end.Episode = 1 << 30
+	}
+	return term{kind: termRange, from: start, to: end}, nil
+}
+
+// Select returns the selected episodes in season and episode order.
+// absolute maps episode IDs to absolute numbers and is only needed for
+// absolute terms.
+func (s *Selector) Select(items []api.Item, absolute map[string]int) []api.Item {
+	sorted := append([]api.Item(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compare(sorted[i].ParentIndexNumber, sorted[i].IndexNumber, Ref{sorted[j].ParentIndexNumber, sorted[j].IndexNumber}) < 0
+	})
+	if s == nil {
+		return sorted
+	}
+
+	var regular []api.Item
+	for _, ep := range sorted {
+		if ep.ParentIndexNumber > 0 {
+			regular = append(regular, ep)
+		}
+	}
+
+	selected := map[string]bool{}
+	for _, t := range s.terms {
+		switch t.kind {
+		case termFirst:
+			for i := 0; i < t.count && i < len(regular); i++ {
+				selected[regular[i].Id] = true
+			}
+		case termLatest:
+			for i := len(regular) - t.count; i < len(regular); i++ {
+				if i >= 0 {
+					selected[regular[i].Id] = true
+				}
+			}
+		default:
+			for _, ep := range sorted {
+				if t.matches(ep, absolute) {
+					selected[ep.Id] = true
+				}
+			}
+		}
+	}
+
+	var out []api.Item
+	for _, ep := range sorted {
+		if selected[ep.Id] {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+func (t term) matches(ep api.Item, absolute map[string]int) bool {
+	season, episode := ep.ParentIndexNumber, ep.IndexNumber
+	switch t.kind {
+	case termAfter:
+		return season > 0 && compare(season, episode, t.from) > 0
+	case termAbsolute:
+		n, ok := absolute[ep.Id]
+		if !ok {
+			return false
+		}
+		last := n
+		if ep.IndexNumberEnd > ep.IndexNumber {
+			last += ep.IndexNumberEnd - ep.IndexNumber
+		}
+		return n <= t.max && last >= t.min
+	}
+	return compare(season, episode, t.from) >= 0 && compare(season, episode, t.to) <= 0
+}
+
+func compare(season, episode int, ref Ref) int {
+	switch {
+	case season != ref.Season:
+		if season < ref.Season {
+			return -1
+		}
+		return 1
+	case episode != ref.Episode:
+		if episode < ref.Episode {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
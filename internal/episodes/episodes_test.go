@@ -0,0 +1,77 @@
+package episodes
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+func testSeries() ([]api.Item, map[string]int) {
+	var items []api.Item
+	absolute := map[string]int{}
+	items = append(items, api.Item{Id: "s0e1", ParentIndexNumber: 0, IndexNumber: 1})
+	n := 1
+	for season := 1; season <= 3; season++ {
+		for ep := 1; ep <= 4; ep++ {
+			id := fmt.Sprintf("s%de%d", season, ep)
+			items = append(items, api.Item{Id: id, ParentIndexNumber: season, IndexNumber: ep})
+			absolute[id] = n
+			n++
+		}
+	}
+	return items, absolute
+}
+
+func ids(items []api.Item) string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Id)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSelect(t *testing.T) {
+	items, absolute := testSeries()
+	cases := map[string]string{
+		"S01E03-S02E02":  "s1e3,s1e4,s2e1,s2e2",
+		"S03":            "s3e1,s3e2,s3e3,s3e4",
+		"s02e01,S02E04":  "s2e1,s2e4",
+		"S02-S03":        "s2e1,s2e2,s2e3,s2e4,s3e1,s3e2,s3e3,s3e4",
+		"latest:2":       "s3e3,s3e4",
+		"first:2":        "s1e1,s1e2",
+		"after:S03E02":   "s3e3,s3e4",
+		"after:S02":      "s3e1,s3e2,s3e3,s3e4",
+		"5-6":            "s2e1,s2e2",
+		"12,S00E01":      "s0e1,s3e4",
+		"first:1,S01E01": "s1e1",
+	}
+	for input, want := range cases {
+		sel, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", input, err)
+		}
+		if got := ids(sel.Select(items, absolute)); got != want {
+			t.Fatalf("Select(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"3-1", "a", "S02-S01", "S02E03-S02E01", "S01-S02E03", "latest:0", "first:x", "after:foo", "next:3", "S01,,S02", "S01E00"} {
+		if _, err := Parse(input); err == nil {
+			t.Fatalf("Parse(%q): expected error", input)
+		}
+	}
+	if sel, err := Parse(""); err != nil || sel != nil {
+		t.Fatalf("Parse(\"\") = %v, %v", sel, err)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("S03E07")
+	if err != nil || ref != (Ref{Season: 3, Episode: 7}) || ref.String() != "S03E07" {
+		t.Fatalf("ParseRef = %+v, %v", ref, err)
+	}
+}
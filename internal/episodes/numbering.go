@@ -0,0 +1,61 @@
+package episodes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+// AbsoluteNumbers numbers regular (non-special) episodes in airing order
+// across seasons. Multi-episode files consume one number per episode.
+func AbsoluteNumbers(items []api.Item) map[string]int {
+	var regular []api.Item
+	for _, ep := range items {
+		if ep.Type != "Episode" && ep.Type != "" {
+			continue
+		}
+		if ep.ParentIndexNumber <= 0 || ep.IndexNumber <= 0 {
+			continue
+		}
+		regular = append(regular, ep)
+	}
+	sort.SliceStable(regular, func(i, j int) bool {
+		if regular[i].ParentIndexNumber == regular[j].ParentIndexNumber {
+			return regular[i].IndexNumber < regular[j].IndexNumber
+		}
+		return regular[i].ParentIndexNumber < regular[j].ParentIndexNumber
+	})
+
+	out := make(map[string]int, len(regular))
+	next := 1
+	for _, ep := range regular {
+		out[ep.Id] = next
+		span := 1
+		if ep.IndexNumberEnd > ep.IndexNumber {
+			span = ep.IndexNumberEnd - ep.IndexNumber + 1
+		}
+		next += span
+	}
+	return out
+}
+
+// Tag renders the numbering part of an episode file name, e.g. S01E01,
+// S01E01-E02, 012 (absolute) or the air date for unnumbered episodes.
+// episodeEnd is 0 unless the file holds several episodes.
+func Tag(season, episode, episodeEnd, absolute int, airDate string) string {
+	if absolute > 0 {
+		if episodeEnd > 0 {
+			return fmt.Sprintf("%03d-%03d", absolute, absolute+episodeEnd-episode)
+		}
+		return fmt.Sprintf("%03d", absolute)
+	}
+	if episode > 0 {
+		tag := fmt.Sprintf("S%02dE%02d", season, episode)
+		if episodeEnd > 0 {
+			tag += fmt.Sprintf("-E%02d", episodeEnd)
+		}
+		return tag
+	}
+	return airDate
+}
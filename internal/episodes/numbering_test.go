@@ -0,0 +1,49 @@
+package episodes
+
+import (
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+func TestAbsoluteNumbers(t *testing.T) {
+	items := []api.Item{
+		{Id: "s2e1", Type: "Episode", ParentIndexNumber: 2, IndexNumber: 1},
+		{Id: "special", Type: "Episode", ParentIndexNumber: 0, IndexNumber: 1},
+		{Id: "s1e3", Type: "Episode", ParentIndexNumber: 1, IndexNumber: 3},
+		{Id: "s1e1", Type: "Episode", ParentIndexNumber: 1, IndexNumber: 1, IndexNumberEnd: 2},
+		{Id: "unnumbered", Type: "Episode", ParentIndexNumber: 1},
+		{Id: "trailer", Type: "Trailer", ParentIndexNumber: 1, IndexNumber: 4},
+	}
+	got := AbsoluteNumbers(items)
+	want := map[string]int{"s1e1": 1, "s1e3": 3, "s2e1": 4}
+	if len(got) != len(want) {
+		t.Fatalf("AbsoluteNumbers() = %v, want %v", got, want)
+	}
+	for id, n := range want {
+		if got[id] != n {
+			t.Fatalf("AbsoluteNumbers()[%q] = %d, want %d", id, got[id], n)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	cases := []struct {
+		season, episode, end, absolute int
+		airDate                        string
+		want                           string
+	}{
+		{1, 1, 0, 0, "", "S01E01"},
+		{1, 1, 2, 0, "", "S01E01-E02"},
+		{0, 3, 0, 0, "", "S00E03"},
+		{1, 12, 0, 12, "", "012"},
+		{1, 1, 2, 25, "", "025-026"},
+		{0, 0, 0, 0, "2024-05-01", "2024-05-01"},
+		{0, 0, 0, 0, "", ""},
+	}
+	for _, tc := range cases {
+		if got := Tag(tc.season, tc.episode, tc.end, tc.absolute, tc.airDate); got != tc.want {
+			t.Fatalf("Tag(%d, %d, %d, %d, %q) = %q, want %q", tc.season, tc.episode, tc.end, tc.absolute, tc.airDate, got, tc.want)
+		}
+	}
+}
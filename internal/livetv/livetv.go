@@ -0,0 +1,29 @@
+package livetv
+
+import (
+	"strings"
+	"time"
+)
+
+const airTimeLayout = "2006-01-02 1504"
+
+// ParseStart parses a recording's StartDate as returned by the server.
+func ParseStart(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// FileName returns "Program - Episode - Channel - 2006-01-02 1504" for a
+// recording, leaving out the parts that are unknown.
+func FileName(program, episodeTitle, channel string, start time.Time) string {
+	parts := []string{program}
+	if episodeTitle != "" && episodeTitle != program {
+		parts = append(parts, episodeTitle)
+	}
+	if channel != "" {
+		parts = append(parts, channel)
+	}
+	if !start.IsZero() {
+		parts = append(parts, start.Format(airTimeLayout))
+	}
+	return strings.Join(parts, " - ")
+}
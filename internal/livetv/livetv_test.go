@@ -0,0 +1,38 @@
+package livetv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileName(t *testing.T) {
+	start := time.Date(2024, 5, 1, 20, 15, 0, 0, time.UTC)
+	cases := []struct {
+		program, episode, channel string
+		start                     time.Time
+		want                      string
+	}{
+		{"News", "", "BBC One", start, "News - BBC One - 2024-05-01 2015"},
+		{"Doctor Who", "Boom", "BBC One", start, "Doctor Who - Boom - BBC One - 2024-05-01 2015"},
+		{"Film", "Film", "", start, "Film - 2024-05-01 2015"},
+		{"Film", "", "", time.Time{}, "Film"},
+	}
+	for _, tc := range cases {
+		if got := FileName(tc.program, tc.episode, tc.channel, tc.start); got != tc.want {
+			t.Fatalf("FileName(%q, %q, %q) = %q, want %q", tc.program, tc.episode, tc.channel, got, tc.want)
+		}
+	}
+}
+
+func TestParseStart(t *testing.T) {
+	got, err := ParseStart("2024-05-01T20:15:00.0000000Z")
+	if err != nil {
+		t.Fatalf("ParseStart: %v", err)
+	}
+	if !got.Equal(time.Date(2024, 5, 1, 20, 15, 0, 0, time.UTC)) {
+		t.Fatalf("ParseStart = %v", got)
+	}
+	if _, err := ParseStart("yesterday"); err == nil {
+		t.Fatalf("expected error for invalid date")
+	}
+}
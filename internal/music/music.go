@@ -0,0 +1,65 @@
+package music
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+const ticksPerMillisecond = 10000
+
+// TrackFileName returns "NN - Title", or "D-NN - Title" for albums that span
+// more than one disc.
+func TrackFileName(disc, track int, multiDisc bool, title string) string {
+	if track <= 0 {
+		return title
+	}
+	if multiDisc && disc > 0 {
+		return fmt.Sprintf("%d-%02d - %s", disc, track, title)
+	}
+	return fmt.Sprintf("%02d - %s", track, title)
+}
+
+func AlbumFolderName(album string, year int) string {
+	if album == "" {
+		album = "Unknown Album"
+	}
+	if year > 0 {
+		return fmt.Sprintf("%s (%d)", album, year)
+	}
+	return album
+}
+
+func ArtistName(item api.Item) string {
+	if item.AlbumArtist != "" {
+		return item.AlbumArtist
+	}
+	if len(item.Artists) > 0 {
+		return item.Artists[0]
+	}
+	return "Unknown Artist"
+}
+
+// IsSynced reports whether the lyrics carry timestamps.
+func IsSynced(lines []api.LyricLine) bool {
+	for _, line := range lines {
+		if line.Start != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatLRC renders timestamped lyric lines as an LRC file.
+func FormatLRC(lines []api.LyricLine) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line.Start == nil {
+			continue
+		}
+		ms := *line.Start / ticksPerMillisecond
+		fmt.Fprintf(&b, "[%02d:%02d.%02d]%s\n", ms/60000, (ms/1000)%60, (ms%1000)/10, line.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package music
+
+import (
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+func TestTrackFileName(t *testing.T) {
+	cases := []struct {
+		disc, track int
+		multi       bool
+		want        string
+	}{
+		{disc: 1, track: 3, want: "03 - Song"},
+		{disc: 2, track: 3, multi: true, want: "2-03 - Song"},
+		{disc: 0, track: 12, multi: true, want: "12 - Song"},
+		{track: 0, want: "Song"},
+	}
+	for _, tc := range cases {
+		if got := TrackFileName(tc.disc, tc.track, tc.multi, "Song"); got != tc.want {
+			t.Fatalf("TrackFileName(%d, %d, %v) = %q, want %q", tc.disc, tc.track, tc.multi, got, tc.want)
+		}
+	}
+}
+
+func TestArtistAndAlbum(t *testing.T) {
+	if got := ArtistName(api.Item{Artists: []string{"A", "B"}}); got != "A" {
+		t.Fatalf("ArtistName fallback = %q", got)
+	}
+	if got := ArtistName(api.Item{}); got != "Unknown Artist" {
+		t.Fatalf("ArtistName empty = %q", got)
+	}
+	if got := AlbumFolderName("Blue", 1971); got != "Blue (1971)" {
+		t.Fatalf("AlbumFolderName = %q", got)
+	}
+}
+
+func TestFormatLRC(t *testing.T) {
+	start := func(ms int64) *int64 {
+		v := ms * ticksPerMillisecond
+		return &v
+	}
+	lines := []api.LyricLine{
+		{Text: "Hello", Start: start(1500)},
+		{Text: "World", Start: start(61230)},
+	}
+	if !IsSynced(lines) {
+		t.Fatalf("expected synced lyrics")
+	}
+	want := "[00:01.50]Hello\n[01:01.23]World\n"
+	if got := FormatLRC(lines); got != want {
+		t.Fatalf("FormatLRC = %q, want %q", got, want)
+	}
+	if IsSynced([]api.LyricLine{{Text: "plain"}}) {
+		t.Fatalf("expected unsynced lyrics")
+	}
+}
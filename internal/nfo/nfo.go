@@ -0,0 +1,34 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type CollectionMovie struct {
+	Title string `xml:"title"`
+	Year  int    `xml:"year,omitempty"`
+	File  string `xml:"file,omitempty"`
+}
+
+// Collection is written as collection.nfo next to the collection artwork so
+// offline players can rebuild the set.
+type Collection struct {
+	XMLName xml.Name          `xml:"collection"`
+	Title   string            `xml:"title"`
+	Plot    string            `xml:"plot,omitempty"`
+	Movies  []CollectionMovie `xml:"movie"`
+}
+
+func Write(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,39 @@
+package nfo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCollection(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, Collection{
+		Title: "Alien Collection",
+		Plot:  "In space & beyond",
+		Movies: []CollectionMovie{
+			{Title: "Alien", Year: 1979, File: "../../Alien (1979)/Alien (1979).mkv"},
+			{Title: "Aliens"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<collection>
+  <title>Alien Collection</title>
+  <plot>In space &amp; beyond</plot>
+  <movie>
+    <title>Alien</title>
+    <year>1979</year>
+    <file>../../Alien (1979)/Alien (1979).mkv</file>
+  </movie>
+  <movie>
+    <title>Aliens</title>
+  </movie>
+</collection>
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected nfo:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,49 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Entry struct {
+	Path     string
+	Title    string
+	Duration int
+}
+
+// WriteM3U8 writes entries as an extended M3U playlist. Paths are written
+// relative to baseDir, the directory the playlist file lives in.
+func WriteM3U8(w io.Writer, baseDir string, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#EXTM3U")
+	for _, e := range entries {
+		rel, err := filepath.Rel(baseDir, e.Path)
+		if err != nil {
+			rel = e.Path
+		}
+		duration := e.Duration
+		if duration <= 0 {
+			duration = -1
+		}
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n", duration, e.Title)
+		fmt.Fprintln(bw, filepath.ToSlash(rel))
+	}
+	return bw.Flush()
+}
+
+// ReadM3U returns the file entries of an M3U playlist in order.
+func ReadM3U(r io.Reader) ([]string, error) {
+	var out []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, scanner.Err()
+}
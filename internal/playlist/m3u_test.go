@@ -0,0 +1,34 @@
+package playlist
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteAndReadM3U8(t *testing.T) {
+	base := filepath.Join("out")
+	entries := []Entry{
+		{Path: filepath.Join("out", "Artist", "Album (2020)", "01 - Song.flac"), Title: "Song", Duration: 215},
+		{Path: filepath.Join("out", "Movie (2024)", "Movie (2024).mkv"), Title: "Movie"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteM3U8(&buf, base, entries); err != nil {
+		t.Fatalf("WriteM3U8: %v", err)
+	}
+	want := "#EXTM3U\n#EXTINF:215,Song\nArtist/Album (2020)/01 - Song.flac\n#EXTINF:-1,Movie\nMovie (2024)/Movie (2024).mkv\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected playlist:\n%s", buf.String())
+	}
+
+	paths, err := ReadM3U(&buf)
+	if err != nil {
+		t.Fatalf("ReadM3U: %v", err)
+	}
+	wantPaths := []string{"Artist/Album (2020)/01 - Song.flac", "Movie (2024)/Movie (2024).mkv"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Fatalf("ReadM3U = %v, want %v", paths, wantPaths)
+	}
+}
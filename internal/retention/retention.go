@@ -0,0 +1,112 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+)
+
+// File is a finished download considered for removal.
+type File struct {
+	DownloadID   int64
+	SeriesID     string
+	SeriesName   string
+	Season       int
+	Episode      int
+	Path         string
+	Size         int64
+	DownloadedAt time.Time
+	Played       bool
+}
+
+type Removal struct {
+	File   File
+	Reason string
+}
+
+// Plan returns the files that policy wants removed at now, with the reason
+// for each. Series rules are applied first; if the remaining files still
+// exceed the total size limit, the oldest watched files are evicted, then the
+// oldest unwatched ones.
+func Plan(files []File, policy *config.RetentionPolicy, maxTotal int64, now time.Time) []Removal {
+	if policy == nil {
+		return nil
+	}
+	removed := map[int]string{}
+
+	bySeries := map[string][]int{}
+	for i, f := range files {
+		rules := policy.ForSeries(f.SeriesID, f.SeriesName)
+		switch {
+		case rules.DeleteWatched != nil && *rules.DeleteWatched && f.Played:
+			removed[i] = "watched"
+		case rules.MaxAgeDays > 0 && now.Sub(f.DownloadedAt) > time.Duration(rules.MaxAgeDays)*24*time.Hour:
+			removed[i] = "older than " + pluralDays(rules.MaxAgeDays)
+		}
+		if f.SeriesID != "" {
+			bySeries[f.SeriesID] = append(bySeries[f.SeriesID], i)
+		}
+	}
+
+	for _, idx := range bySeries {
+		first := files[idx[0]]
+		keep := policy.ForSeries(first.SeriesID, first.SeriesName).KeepLatest
+		if keep <= 0 || len(idx) <= keep {
+			continue
+		}
+		sort.SliceStable(idx, func(a, b int) bool {
+			fa, fb := files[idx[a]], files[idx[b]]
+			if fa.Season != fb.Season {
+				return fa.Season > fb.Season
+			}
+			return fa.Episode > fb.Episode
+		})
+		for _, i := range idx[keep:] {
+			if _, ok := removed[i]; !ok {
+				removed[i] = "not among the latest episodes"
+			}
+		}
+	}
+
+	if maxTotal > 0 {
+		var total int64
+		var remaining []int
+		for i, f := range files {
+			if _, ok := removed[i]; !ok {
+				total += f.Size
+				remaining = append(remaining, i)
+			}
+		}
+		sort.SliceStable(remaining, func(a, b int) bool {
+			fa, fb := files[remaining[a]], files[remaining[b]]
+			if fa.Played != fb.Played {
+				return fa.Played
+			}
+			return fa.DownloadedAt.Before(fb.DownloadedAt)
+		})
+		for _, i := range remaining {
+			if total <= maxTotal {
+				break
+			}
+			removed[i] = "over total size limit"
+			total -= files[i].Size
+		}
+	}
+
+	var out []Removal
+	for i, f := range files {
+		if reason, ok := removed[i]; ok {
+			out = append(out, Removal{File: f, Reason: reason})
+		}
+	}
+	return out
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
@@ -0,0 +1,78 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+)
+
+func reasons(removals []Removal) map[int64]string {
+	out := map[int64]string{}
+	for _, r := range removals {
+		out[r.File.DownloadID] = r.Reason
+	}
+	return out
+}
+
+func TestPlanSeriesRules(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	yes := true
+	two := 2
+	policy := &config.RetentionPolicy{
+		Retention: config.Retention{DeleteWatched: &yes, MaxAgeDays: 30},
+		Series: map[string]config.RetentionOverride{
+			"show": {KeepLatest: &two},
+		},
+	}
+	files := []File{
+		{DownloadID: 1, SeriesID: "show", Season: 1, Episode: 1, DownloadedAt: now},
+		{DownloadID: 2, SeriesID: "show", Season: 1, Episode: 2, DownloadedAt: now},
+		{DownloadID: 3, SeriesID: "show", Season: 2, Episode: 1, DownloadedAt: now},
+		{DownloadID: 4, DownloadedAt: now, Played: true},
+		{DownloadID: 5, DownloadedAt: now.AddDate(0, 0, -31)},
+		{DownloadID: 6, DownloadedAt: now.AddDate(0, 0, -5)},
+	}
+
+	got := reasons(Plan(files, policy, 0, now))
+	want := map[int64]string{
+		1: "not among the latest episodes",
+		4: "watched",
+		5: "older than 30 days",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan = %v, want %v", got, want)
+	}
+	for id, reason := range want {
+		if got[id] != reason {
+			t.Fatalf("download %d: reason %q, want %q (all: %v)", id, got[id], reason, got)
+		}
+	}
+}
+
+func TestPlanTotalSizeEvictsWatchedFirst(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := &config.RetentionPolicy{}
+	files := []File{
+		{DownloadID: 1, Size: 10, DownloadedAt: now.AddDate(0, 0, -10)},
+		{DownloadID: 2, Size: 10, DownloadedAt: now.AddDate(0, 0, -2), Played: true},
+		{DownloadID: 3, Size: 10, DownloadedAt: now.AddDate(0, 0, -1), Played: true},
+		{DownloadID: 4, Size: 10, DownloadedAt: now},
+	}
+
+	got := reasons(Plan(files, policy, 25, now))
+	if len(got) != 2 || got[2] == "" || got[3] == "" {
+		t.Fatalf("expected watched downloads 2 and 3 to be evicted, got %v", got)
+	}
+
+	got = reasons(Plan(files, policy, 15, now))
+	if len(got) != 3 || got[1] == "" || got[4] != "" {
+		t.Fatalf("expected the oldest unwatched download to be evicted after the watched ones, got %v", got)
+	}
+}
+
+func TestPlanWithoutPolicy(t *testing.T) {
+	if got := Plan([]File{{DownloadID: 1, Played: true}}, nil, 0, time.Now()); got != nil {
+		t.Fatalf("expected no removals, got %v", got)
+	}
+}
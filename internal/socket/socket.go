@@ -0,0 +1,153 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultKeepAlive = 30 * time.Second
+	minBackoff       = time.Second
+	maxBackoff       = time.Minute
+)
+
+// Message is a message on the Jellyfin /socket WebSocket.
+type Message struct {
+	MessageType string          `json:"MessageType"`
+	Data        json.RawMessage `json:"Data,omitempty"`
+}
+
+// LibraryChanged is the Data of a LibraryChanged message.
+type LibraryChanged struct {
+	ItemsAdded   []string `json:"ItemsAdded"`
+	ItemsUpdated []string `json:"ItemsUpdated"`
+	ItemsRemoved []string `json:"ItemsRemoved"`
+}
+
+// UserDataChanged is the Data of a UserDataChanged message.
+type UserDataChanged struct {
+	UserId       string         `json:"UserId"`
+	UserDataList []UserDataItem `json:"UserDataList"`
+}
+
+type UserDataItem struct {
+	ItemId     string `json:"ItemId"`
+	Played     bool   `json:"Played"`
+	IsFavorite bool   `json:"IsFavorite"`
+}
+
+// listen connects to url, calls onConnect once connected and handle for
+// every message until ctx is done or the connection fails. handle runs on
+// the read loop and must not block for long, or the connection times out.
+// It answers the server's ForceKeepAlive by sending KeepAlive messages at
+// half the requested interval, and treats a connection that stays silent
+// for two intervals as dead.
+func listen(ctx context.Context, url string, header http.Header, onConnect func(), handle func(Message)) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if onConnect != nil {
+		onConnect()
+	}
+
+	var (
+		mu       sync.Mutex
+		interval = defaultKeepAlive
+	)
+	send := func(msg Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return conn.WriteJSON(msg)
+	}
+	keepAlive := func() time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return interval
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-time.After(keepAlive() / 2):
+				if err := send(Message{MessageType: "KeepAlive"}); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * keepAlive()))
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		switch msg.MessageType {
+		case "ForceKeepAlive":
+			var seconds int
+			if json.Unmarshal(msg.Data, &seconds) == nil && seconds > 0 {
+				mu.Lock()
+				interval = time.Duration(seconds) * time.Second
+				mu.Unlock()
+			}
+			if err := send(Message{MessageType: "KeepAlive"}); err != nil {
+				return err
+			}
+		case "KeepAlive":
+		default:
+			handle(msg)
+		}
+	}
+}
+
+// Run keeps a connection open with listen, reconnecting with exponential
+// backoff (1s up to 1m) until ctx is done. onConnect is called after every
+// successful (re)connect, so callers can catch up on changes they missed
+// while disconnected; onError is called with every connection error.
+func Run(ctx context.Context, url string, header http.Header, onConnect func(), handle func(Message), onError func(error)) error {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := listen(ctx, url, header, onConnect, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			err = errors.New("connection closed")
+		}
+		onError(err)
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
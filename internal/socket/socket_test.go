@@ -0,0 +1,100 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestListenKeepAliveAndMessages(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	keepAlives := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "token" {
+			t.Errorf("missing api_key in %s", r.URL.RawQuery)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(Message{MessageType: "ForceKeepAlive", Data: json.RawMessage(`60`)})
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil || msg.MessageType != "KeepAlive" {
+			t.Errorf("expected KeepAlive, got %+v, %v", msg, err)
+			return
+		}
+		keepAlives <- struct{}{}
+		_ = conn.WriteJSON(Message{MessageType: "KeepAlive"})
+		_ = conn.WriteJSON(Message{MessageType: "LibraryChanged", Data: json.RawMessage(`{"ItemsAdded":["a","b"]}`)})
+		_ = conn.ReadJSON(&msg)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/socket?api_key=token"
+
+	var got []Message
+	err := listen(ctx, url, nil, nil, func(msg Message) {
+		got = append(got, msg)
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatalf("listen = %v, want context.Canceled", err)
+	}
+	if len(keepAlives) != 1 {
+		t.Fatalf("expected a KeepAlive reply")
+	}
+	if len(got) != 1 || got[0].MessageType != "LibraryChanged" {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+	var changed LibraryChanged
+	if err := json.Unmarshal(got[0].Data, &changed); err != nil || strings.Join(changed.ItemsAdded, ",") != "a,b" {
+		t.Fatalf("LibraryChanged = %+v, %v", changed, err)
+	}
+}
+
+func TestRunReconnects(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var connections int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		n := atomic.AddInt32(&connections, 1)
+		if n == 1 {
+			conn.Close()
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(Message{MessageType: "LibraryChanged", Data: json.RawMessage(`{}`)})
+		var msg Message
+		_ = conn.ReadJSON(&msg)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	var connects, errs int
+	err := Run(ctx, url, nil, func() { connects++ }, func(Message) { cancel() }, func(error) { errs++ })
+	if err != context.Canceled {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if errs != 1 || atomic.LoadInt32(&connections) != 2 {
+		t.Fatalf("expected one failed and one good connection, got %d errors and %d connections", errs, connections)
+	}
+	if connects != 2 {
+		t.Fatalf("expected onConnect for both connections, got %d", connects)
+	}
+}
@@ -12,8 +12,25 @@ import (
 
 const (
 	dbFileName = "jellyfin.db"
+
+	downloadColumns = `id, item_id, item_name, item_type, series_id, season_number, episode_number, status, bytes_total, bytes_done, path, error, profile, version, parent_id, part, source_id, source_size, source_etag, created_at, updated_at`
 )
 
+// downloadMigrations lists columns added to the downloads table after the
+// initial schema. They are applied in order on Open when missing.
+var downloadMigrations = []struct {
+	column     string
+	definition string
+}{
+	{column: "profile", definition: "TEXT"},
+	{column: "version", definition: "TEXT"},
+	{column: "parent_id", definition: "TEXT"},
+	{column: "part", definition: "INTEGER"},
+	{column: "source_id", definition: "TEXT"},
+	{column: "source_size", definition: "INTEGER"},
+	{column: "source_etag", definition: "TEXT"},
+}
+
 type Store struct {
 	db *sql.DB
 }
@@ -31,8 +48,17 @@ type Download struct {
 	BytesDone     sql.NullInt64
 	Path          string
 	Error         sql.NullString
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	Profile       sql.NullString
+	Version       sql.NullString
+	ParentID      sql.NullString
+	Part          sql.NullInt64
+	// SourceID, SourceSize and SourceETag describe the server file the
+	// download was made from, to detect when it is replaced.
+	SourceID   sql.NullString
+	SourceSize sql.NullInt64
+	SourceETag sql.NullString
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 func DBPath(storeDir string) string {
@@ -88,10 +114,62 @@ CREATE TABLE IF NOT EXISTS series_progress (
 	last_episode INTEGER,
 	updated_at TEXT NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS sync_items (
+	rule TEXT NOT NULL,
+	item_id TEXT NOT NULL,
+	item_name TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (rule, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+	series_id TEXT PRIMARY KEY,
+	series_name TEXT NOT NULL,
+	from_season INTEGER,
+	from_episode INTEGER,
+	keep_latest INTEGER,
+	last_checked TEXT,
+	created_at TEXT NOT NULL
+);
 `)
 	if err != nil {
 		return fmt.Errorf("init schema: %w", err)
 	}
+	return s.migrate()
+}
+
+func (s *Store) migrate() error {
+	rows, err := s.db.Query(`PRAGMA table_info(downloads)`)
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("read schema: %w", err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	for _, m := range downloadMigrations {
+		if existing[m.column] {
+			continue
+		}
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE downloads ADD COLUMN %s %s", m.column, m.definition)); err != nil {
+			return fmt.Errorf("migrate downloads.%s: %w", m.column, err)
+		}
+	}
 	return nil
 }
 
@@ -108,8 +186,9 @@ func (s *Store) UpsertDownload(d *Download) (int64, error) {
 	res, err := s.db.Exec(`
 INSERT INTO downloads (
 	item_id, item_name, item_type, series_id, season_number, episode_number,
-	status, bytes_total, bytes_done, path, error, created_at, updated_at
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	status, bytes_total, bytes_done, path, error, profile, version, parent_id, part,
+	source_id, source_size, source_etag, created_at, updated_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(item_id, path) DO UPDATE SET
 	item_name=excluded.item_name,
 	item_type=excluded.item_type,
@@ -120,6 +199,13 @@ ON CONFLICT(item_id, path) DO UPDATE SET
 	bytes_total=excluded.bytes_total,
 	bytes_done=excluded.bytes_done,
 	error=excluded.error,
+	profile=excluded.profile,
+	version=excluded.version,
+	parent_id=excluded.parent_id,
+	part=excluded.part,
+	source_id=excluded.source_id,
+	source_size=excluded.source_size,
+	source_etag=excluded.source_etag,
 	updated_at=excluded.updated_at
 `,
 		d.ItemID,
@@ -133,6 +219,13 @@ ON CONFLICT(item_id, path) DO UPDATE SET
 		nullInt(d.BytesDone),
 		d.Path,
 		nullString(d.Error),
+		nullString(d.Profile),
+		nullString(d.Version),
+		nullString(d.ParentID),
+		nullInt(d.Part),
+		nullString(d.SourceID),
+		nullInt(d.SourceSize),
+		nullString(d.SourceETag),
 		d.CreatedAt.Format(time.RFC3339Nano),
 		d.UpdatedAt.Format(time.RFC3339Nano),
 	)
@@ -158,6 +251,14 @@ func (s *Store) UpdateDownloadProgress(id int64, bytesDone, bytesTotal int64) er
 	return nil
 }
 
+func (s *Store) UpdateDownloadPath(id int64, path string) error {
+	_, err := s.db.Exec(`UPDATE downloads SET path = ?, updated_at = ? WHERE id = ?`, path, time.Now().UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return fmt.Errorf("update download path: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) SetDownloadStatus(id int64, status string, errMsg string) error {
 	_, err := s.db.Exec(`UPDATE downloads SET status = ?, error = ?, updated_at = ? WHERE id = ?`, status, nullString(errMsg), time.Now().UTC().Format(time.RFC3339Nano), id)
 	if err != nil {
@@ -167,7 +268,7 @@ func (s *Store) SetDownloadStatus(id int64, status string, errMsg string) error
 }
 
 func (s *Store) ListDownloads(status string) ([]Download, error) {
-	query := `SELECT id, item_id, item_name, item_type, series_id, season_number, episode_number, status, bytes_total, bytes_done, path, error, created_at, updated_at FROM downloads`
+	query := `SELECT ` + downloadColumns + ` FROM downloads`
 	args := []interface{}{}
 	if status != "" {
 		query += " WHERE status = ?"
@@ -183,33 +284,99 @@ func (s *Store) ListDownloads(status string) ([]Download, error) {
 
 	var out []Download
 	for rows.Next() {
-		var d Download
-		var created, updated string
-		if err := rows.Scan(&d.ID, &d.ItemID, &d.ItemName, &d.ItemType, &d.SeriesID, &d.SeasonNumber, &d.EpisodeNumber, &d.Status, &d.BytesTotal, &d.BytesDone, &d.Path, &d.Error, &created, &updated); err != nil {
+		d, err := scanDownload(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan download: %w", err)
 		}
-		d.CreatedAt = parseTime(created)
-		d.UpdatedAt = parseTime(updated)
-		out = append(out, d)
+		out = append(out, *d)
 	}
 	return out, nil
 }
 
 func (s *Store) GetDownload(id int64) (*Download, error) {
-	row := s.db.QueryRow(`SELECT id, item_id, item_name, item_type, series_id, season_number, episode_number, status, bytes_total, bytes_done, path, error, created_at, updated_at FROM downloads WHERE id = ?`, id)
-	var d Download
-	var created, updated string
-	if err := row.Scan(&d.ID, &d.ItemID, &d.ItemName, &d.ItemType, &d.SeriesID, &d.SeasonNumber, &d.EpisodeNumber, &d.Status, &d.BytesTotal, &d.BytesDone, &d.Path, &d.Error, &created, &updated); err != nil {
+	row := s.db.QueryRow(`SELECT `+downloadColumns+` FROM downloads WHERE id = ?`, id)
+	d, err := scanDownload(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get download: %w", err)
 	}
+	return d, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDownload(row rowScanner) (*Download, error) {
+	var d Download
+	var created, updated string
+	if err := row.Scan(&d.ID, &d.ItemID, &d.ItemName, &d.ItemType, &d.SeriesID, &d.SeasonNumber, &d.EpisodeNumber, &d.Status, &d.BytesTotal, &d.BytesDone, &d.Path, &d.Error, &d.Profile, &d.Version, &d.ParentID, &d.Part, &d.SourceID, &d.SourceSize, &d.SourceETag, &created, &updated); err != nil {
+		return nil, err
+	}
 	d.CreatedAt = parseTime(created)
 	d.UpdatedAt = parseTime(updated)
 	return &d, nil
 }
 
+// LatestDownload returns the most recently updated record for itemID with
+// the given status, or nil when there is none.
+func (s *Store) LatestDownload(itemID, status string) (*Download, error) {
+	row := s.db.QueryRow(`SELECT `+downloadColumns+` FROM downloads WHERE item_id = ? AND status = ? ORDER BY updated_at DESC LIMIT 1`, itemID, status)
+	d, err := scanDownload(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("latest download: %w", err)
+	}
+	return d, nil
+}
+
+// WasDownloaded reports whether itemID finished downloading at some point,
+// including downloads that were removed locally since.
+func (s *Store) WasDownloaded(itemID string) (bool, error) {
+	var found bool
+	row := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM downloads WHERE item_id = ? AND status IN ('done', 'removed'))`, itemID)
+	if err := row.Scan(&found); err != nil {
+		return false, fmt.Errorf("was downloaded: %w", err)
+	}
+	return found, nil
+}
+
+// ItemFiles returns the local files downloaded for itemID in playback
+// order: every part of a multi-part movie, or the single tracked file.
+func (s *Store) ItemFiles(itemID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT path FROM downloads WHERE parent_id = ? AND part IS NOT NULL AND status = 'done' ORDER BY part`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("item files: %w", err)
+	}
+	var out []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("item files: %w", err)
+		}
+		out = append(out, path)
+	}
+	rows.Close()
+	if len(out) > 0 {
+		return out, nil
+	}
+
+	row := s.db.QueryRow(`SELECT path FROM downloads WHERE item_id = ? AND status = 'done' ORDER BY updated_at DESC LIMIT 1`, itemID)
+	var path string
+	if err := row.Scan(&path); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("item files: %w", err)
+	}
+	return []string{path}, nil
+}
+
 func (s *Store) UpdateSeriesProgress(seriesID string, season, episode int64) error {
 	if seriesID == "" {
 		return nil
@@ -221,6 +388,8 @@ ON CONFLICT(series_id) DO UPDATE SET
 	last_season=excluded.last_season,
 	last_episode=excluded.last_episode,
 	updated_at=excluded.updated_at
+WHERE excluded.last_season > series_progress.last_season
+	OR (excluded.last_season = series_progress.last_season AND excluded.last_episode > series_progress.last_episode)
 `, seriesID, season, episode, time.Now().UTC().Format(time.RFC3339Nano))
 	if err != nil {
 		return fmt.Errorf("update series progress: %w", err)
@@ -228,6 +397,20 @@ ON CONFLICT(series_id) DO UPDATE SET
 	return nil
 }
 
+// SeriesProgress returns the last downloaded season and episode of a series.
+// ok is false when nothing was downloaded yet.
+func (s *Store) SeriesProgress(seriesID string) (season, episode int64, ok bool, err error) {
+	row := s.db.QueryRow(`SELECT last_season, last_episode FROM series_progress WHERE series_id = ?`, seriesID)
+	var lastSeason, lastEpisode sql.NullInt64
+	if err := row.Scan(&lastSeason, &lastEpisode); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, false, nil
+		}
+		return 0, 0, false, fmt.Errorf("series progress: %w", err)
+	}
+	return lastSeason.Int64, lastEpisode.Int64, true, nil
+}
+
 func nullString(v interface{}) interface{} {
 	switch x := v.(type) {
 	case sql.NullString:
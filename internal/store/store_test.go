@@ -2,6 +2,7 @@ package store
 
 import (
 	"database/sql"
+	"fmt"
 	"path/filepath"
 	"testing"
 
@@ -102,3 +103,124 @@ func TestDBPath(t *testing.T) {
 		t.Fatalf("DBPath unexpected: %s", got)
 	}
 }
+
+func TestMigrateAddsColumns(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", "file:"+DBPath(dir))
+	if err != nil {
+		t.Fatalf("sql open: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE downloads (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id TEXT NOT NULL,
+	item_name TEXT NOT NULL,
+	item_type TEXT NOT NULL,
+	series_id TEXT,
+	season_number INTEGER,
+	episode_number INTEGER,
+	status TEXT NOT NULL,
+	bytes_total INTEGER,
+	bytes_done INTEGER,
+	path TEXT NOT NULL,
+	error TEXT,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+)`); err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	db.Close()
+
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	id, err := st.UpsertDownload(&Download{
+		ItemID:     "item-1",
+		ItemName:   "Test Movie",
+		ItemType:   "Movie",
+		Path:       filepath.Join(dir, "test.mp4"),
+		Profile:    sql.NullString{String: "mobile-720p", Valid: true},
+		SourceID:   sql.NullString{String: "src-1", Valid: true},
+		SourceSize: sql.NullInt64{Int64: 4096, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("UpsertDownload: %v", err)
+	}
+	row, err := st.GetDownload(id)
+	if err != nil {
+		t.Fatalf("GetDownload: %v", err)
+	}
+	if row.Profile.String != "mobile-720p" {
+		t.Fatalf("expected profile to round-trip, got %+v", row.Profile)
+	}
+	if row.SourceID.String != "src-1" || row.SourceSize.Int64 != 4096 || row.SourceETag.Valid {
+		t.Fatalf("expected source fields to round-trip, got %+v %+v %+v", row.SourceID, row.SourceSize, row.SourceETag)
+	}
+}
+
+func TestWasDownloaded(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	for itemID, status := range map[string]string{"done": "done", "pruned": "removed", "failed": "failed"} {
+		if _, err := st.UpsertDownload(&Download{ItemID: itemID, ItemName: itemID, ItemType: "Episode", Path: filepath.Join(dir, itemID+".mkv"), Status: status}); err != nil {
+			t.Fatalf("UpsertDownload: %v", err)
+		}
+	}
+	for itemID, want := range map[string]bool{"done": true, "pruned": true, "failed": false, "missing": false} {
+		got, err := st.WasDownloaded(itemID)
+		if err != nil {
+			t.Fatalf("WasDownloaded(%s): %v", itemID, err)
+		}
+		if got != want {
+			t.Fatalf("WasDownloaded(%s) = %v, want %v", itemID, got, want)
+		}
+	}
+}
+
+func TestItemFiles(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	single := filepath.Join(dir, "single.mkv")
+	if _, err := st.UpsertDownload(&Download{ItemID: "single", ItemName: "Single", ItemType: "Movie", Path: single, Status: "done"}); err != nil {
+		t.Fatalf("UpsertDownload: %v", err)
+	}
+	for _, part := range []int64{2, 1} {
+		_, err := st.UpsertDownload(&Download{
+			ItemID:   fmt.Sprintf("stacked-%d", part),
+			ItemName: "Stacked",
+			ItemType: "Movie",
+			Path:     filepath.Join(dir, fmt.Sprintf("stacked-pt%d.mkv", part)),
+			Status:   "done",
+			ParentID: sql.NullString{String: "stacked", Valid: true},
+			Part:     sql.NullInt64{Int64: part, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("UpsertDownload: %v", err)
+		}
+	}
+
+	files, err := st.ItemFiles("single")
+	if err != nil || len(files) != 1 || files[0] != single {
+		t.Fatalf("ItemFiles(single) = %v, %v", files, err)
+	}
+	files, err = st.ItemFiles("stacked")
+	if err != nil || len(files) != 2 || filepath.Base(files[0]) != "stacked-pt1.mkv" {
+		t.Fatalf("ItemFiles(stacked) = %v, %v", files, err)
+	}
+	files, err = st.ItemFiles("missing")
+	if err != nil || len(files) != 0 {
+		t.Fatalf("ItemFiles(missing) = %v, %v", files, err)
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Subscription struct {
+	SeriesID    string
+	SeriesName  string
+	FromSeason  sql.NullInt64
+	FromEpisode sql.NullInt64
+	KeepLatest  sql.NullInt64
+	LastChecked sql.NullString
+	CreatedAt   time.Time
+}
+
+func (s *Store) AddSubscription(sub *Subscription) error {
+	_, err := s.db.Exec(`
+INSERT INTO subscriptions (series_id, series_name, from_season, from_episode, keep_latest, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(series_id) DO UPDATE SET
+	series_name=excluded.series_name,
+	from_season=excluded.from_season,
+	from_episode=excluded.from_episode,
+	keep_latest=excluded.keep_latest
+`, sub.SeriesID, sub.SeriesName, nullInt(sub.FromSeason), nullInt(sub.FromEpisode), nullInt(sub.KeepLatest), time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("add subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListSubscriptions() ([]Subscription, error) {
+	rows, err := s.db.Query(`SELECT series_id, series_name, from_season, from_episode, keep_latest, last_checked, created_at FROM subscriptions ORDER BY series_name`)
+	if err != nil {
+		return nil, fmt.Errorf("list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Subscription
+	for rows.Next() {
+		var (
+			sub       Subscription
+			createdAt string
+		)
+		if err := rows.Scan(&sub.SeriesID, &sub.SeriesName, &sub.FromSeason, &sub.FromEpisode, &sub.KeepLatest, &sub.LastChecked, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		sub.CreatedAt = parseTime(createdAt)
+		out = append(out, sub)
+	}
+	return out, rows.Err()
+}
+
+// RemoveSubscription deletes the subscription of seriesID and reports
+// whether one existed.
+func (s *Store) RemoveSubscription(seriesID string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM subscriptions WHERE series_id = ?`, seriesID)
+	if err != nil {
+		return false, fmt.Errorf("remove subscription: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *Store) MarkSubscriptionChecked(seriesID string) error {
+	_, err := s.db.Exec(`UPDATE subscriptions SET last_checked = ? WHERE series_id = ?`, time.Now().UTC().Format(time.RFC3339Nano), seriesID)
+	if err != nil {
+		return fmt.Errorf("mark subscription checked: %w", err)
+	}
+	return nil
+}
+
+// SeriesDownloads returns the finished episode downloads of a series, newest
+// episode first.
+func (s *Store) SeriesDownloads(seriesID string) ([]Download, error) {
+	rows, err := s.db.Query(`SELECT `+downloadColumns+` FROM downloads WHERE series_id = ? AND status = 'done' ORDER BY season_number DESC, episode_number DESC, id DESC`, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("series downloads: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Download
+	for rows.Next() {
+		d, err := scanDownload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan download: %w", err)
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestSubscriptions(t *testing.T) {
+	st, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	err = st.AddSubscription(&Subscription{
+		SeriesID:   "series-1",
+		SeriesName: "Show",
+		FromSeason: sql.NullInt64{Int64: 3, Valid: true},
+		KeepLatest: sql.NullInt64{Int64: 5, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("AddSubscription: %v", err)
+	}
+	if err := st.MarkSubscriptionChecked("series-1"); err != nil {
+		t.Fatalf("MarkSubscriptionChecked: %v", err)
+	}
+
+	subs, err := st.ListSubscriptions()
+	if err != nil {
+		t.Fatalf("ListSubscriptions: %v", err)
+	}
+	if len(subs) != 1 || subs[0].FromSeason.Int64 != 3 || subs[0].KeepLatest.Int64 != 5 || !subs[0].LastChecked.Valid {
+		t.Fatalf("unexpected subscriptions: %+v", subs)
+	}
+
+	removed, err := st.RemoveSubscription("series-1")
+	if err != nil || !removed {
+		t.Fatalf("RemoveSubscription = %v, %v", removed, err)
+	}
+	removed, err = st.RemoveSubscription("series-1")
+	if err != nil || removed {
+		t.Fatalf("second RemoveSubscription = %v, %v", removed, err)
+	}
+}
+
+func TestSeriesProgressOnlyMovesForward(t *testing.T) {
+	st, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	if _, _, ok, err := st.SeriesProgress("series-1"); err != nil || ok {
+		t.Fatalf("expected no progress, got ok=%v err=%v", ok, err)
+	}
+	steps := [][2]int64{{2, 3}, {1, 9}, {2, 1}, {3, 1}}
+	for _, step := range steps {
+		if err := st.UpdateSeriesProgress("series-1", step[0], step[1]); err != nil {
+			t.Fatalf("UpdateSeriesProgress: %v", err)
+		}
+	}
+	season, episode, ok, err := st.SeriesProgress("series-1")
+	if err != nil || !ok {
+		t.Fatalf("SeriesProgress: ok=%v err=%v", ok, err)
+	}
+	if season != 3 || episode != 1 {
+		t.Fatalf("expected S03E01, got S%02dE%02d", season, episode)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyncItem records that a sync rule selected an item, so a later sync can
+// remove the local copy once no rule selects it anymore.
+type SyncItem struct {
+	Rule      string
+	ItemID    string
+	ItemName  string
+	UpdatedAt time.Time
+}
+
+func (s *Store) SetSyncItem(rule, itemID, itemName string) error {
+	_, err := s.db.Exec(`
+INSERT INTO sync_items (rule, item_id, item_name, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(rule, item_id) DO UPDATE SET
+	item_name=excluded.item_name,
+	updated_at=excluded.updated_at
+`, rule, itemID, itemName, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("set sync item: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) RemoveSyncItem(rule, itemID string) error {
+	if _, err := s.db.Exec(`DELETE FROM sync_items WHERE rule = ? AND item_id = ?`, rule, itemID); err != nil {
+		return fmt.Errorf("remove sync item: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) SyncItems() ([]SyncItem, error) {
+	rows, err := s.db.Query(`SELECT rule, item_id, item_name, updated_at FROM sync_items ORDER BY rule, item_name`)
+	if err != nil {
+		return nil, fmt.Errorf("list sync items: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SyncItem
+	for rows.Next() {
+		var (
+			item    SyncItem
+			updated string
+		)
+		if err := rows.Scan(&item.Rule, &item.ItemID, &item.ItemName, &updated); err != nil {
+			return nil, fmt.Errorf("scan sync item: %w", err)
+		}
+		item.UpdatedAt = parseTime(updated)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+// ItemDownloads returns the finished downloads of an item, including the
+// parts and extras recorded with it as their parent.
+func (s *Store) ItemDownloads(itemID string) ([]Download, error) {
+	rows, err := s.db.Query(`SELECT `+downloadColumns+` FROM downloads WHERE (item_id = ? OR parent_id = ?) AND status = 'done' ORDER BY part, id`, itemID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("item downloads: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Download
+	for rows.Next() {
+		d, err := scanDownload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan download: %w", err)
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
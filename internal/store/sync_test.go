@@ -0,0 +1,65 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncItems(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	for _, item := range []string{"ep-1", "ep-2"} {
+		if err := st.SetSyncItem("series:show", item, item); err != nil {
+			t.Fatalf("SetSyncItem: %v", err)
+		}
+	}
+	if err := st.SetSyncItem("series:show", "ep-1", "renamed"); err != nil {
+		t.Fatalf("SetSyncItem update: %v", err)
+	}
+	if err := st.RemoveSyncItem("series:show", "ep-2"); err != nil {
+		t.Fatalf("RemoveSyncItem: %v", err)
+	}
+	items, err := st.SyncItems()
+	if err != nil {
+		t.Fatalf("SyncItems: %v", err)
+	}
+	if len(items) != 1 || items[0].ItemID != "ep-1" || items[0].ItemName != "renamed" {
+		t.Fatalf("unexpected sync items: %+v", items)
+	}
+}
+
+func TestItemDownloadsIncludesParts(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+
+	records := []*Download{
+		{ItemID: "movie", ItemName: "Movie - pt1", ItemType: "Movie", Path: filepath.Join(dir, "pt1.mkv"), Status: "done",
+			ParentID: sql.NullString{String: "movie", Valid: true}, Part: sql.NullInt64{Int64: 1, Valid: true}},
+		{ItemID: "part-2", ItemName: "Movie - pt2", ItemType: "Movie", Path: filepath.Join(dir, "pt2.mkv"), Status: "done",
+			ParentID: sql.NullString{String: "movie", Valid: true}, Part: sql.NullInt64{Int64: 2, Valid: true}},
+		{ItemID: "other", ItemName: "Other", ItemType: "Movie", Path: filepath.Join(dir, "other.mkv"), Status: "done"},
+	}
+	for _, rec := range records {
+		if _, err := st.UpsertDownload(rec); err != nil {
+			t.Fatalf("UpsertDownload: %v", err)
+		}
+	}
+
+	downloads, err := st.ItemDownloads("movie")
+	if err != nil {
+		t.Fatalf("ItemDownloads: %v", err)
+	}
+	if len(downloads) != 2 || downloads[0].Part.Int64 != 1 || downloads[1].ItemID != "part-2" {
+		t.Fatalf("unexpected downloads: %+v", downloads)
+	}
+}
@@ -0,0 +1,141 @@
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+)
+
+const undeterminedLanguage = "und"
+
+var twoLetterCodes = map[string]string{
+	"ara": "ar", "bul": "bg", "cat": "ca", "ces": "cs", "cze": "cs",
+	"chi": "zh", "zho": "zh", "dan": "da", "deu": "de", "ger": "de",
+	"ell": "el", "gre": "el", "eng": "en", "est": "et", "fas": "fa",
+	"per": "fa", "fin": "fi", "fra": "fr", "fre": "fr", "heb": "he",
+	"hin": "hi", "hrv": "hr", "hun": "hu", "ind": "id", "isl": "is",
+	"ice": "is", "ita": "it", "jpn": "ja", "kor": "ko", "lav": "lv",
+	"lit": "lt", "may": "ms", "msa": "ms", "nld": "nl", "dut": "nl",
+	"nor": "no", "nob": "nb", "nno": "nn", "pol": "pl", "por": "pt",
+	"ron": "ro", "rum": "ro", "rus": "ru", "slk": "sk", "slo": "sk",
+	"slv": "sl", "spa": "es", "srp": "sr", "swe": "sv", "tha": "th",
+	"tur": "tr", "ukr": "uk", "vie": "vi",
+}
+
+var imageCodecs = map[string]struct{}{
+	"pgssub":            {},
+	"hdmv_pgs_subtitle": {},
+	"dvdsub":            {},
+	"dvd_subtitle":      {},
+	"dvbsub":            {},
+	"dvb_subtitle":      {},
+	"vobsub":            {},
+}
+
+var formats = map[string]string{
+	"srt":    "srt",
+	"subrip": "srt",
+	"vtt":    "vtt",
+	"webvtt": "vtt",
+	"ass":    "ass",
+	"ssa":    "ass",
+}
+
+type Selection struct {
+	All       bool
+	Languages []string
+}
+
+// ParseSelection parses --subs values such as "en,de" or "all".
+func ParseSelection(value string) (Selection, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return Selection{}, nil
+	}
+	if value == "all" {
+		return Selection{All: true}, nil
+	}
+
+	var sel Selection
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if part == "all" {
+			return Selection{All: true}, nil
+		}
+		if len(part) < 2 || len(part) > 3 {
+			return Selection{}, fmt.Errorf("invalid subtitle language: %s", part)
+		}
+		sel.Languages = append(sel.Languages, NormalizeLanguage(part))
+	}
+	if len(sel.Languages) == 0 {
+		return Selection{}, fmt.Errorf("no subtitle languages given")
+	}
+	return sel, nil
+}
+
+func (s Selection) Enabled() bool {
+	return s.All || len(s.Languages) > 0
+}
+
+func (s Selection) Matches(language string) bool {
+	if s.All {
+		return true
+	}
+	lang := NormalizeLanguage(language)
+	for _, want := range s.Languages {
+		if want == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLanguage maps ISO 639-2 codes to their two-letter form when one
+// is known, so "ger", "deu" and "de" all compare equal.
+func NormalizeLanguage(code string) string {
+	code = strings.TrimSpace(strings.ToLower(code))
+	if code == "" {
+		return undeterminedLanguage
+	}
+	if short, ok := twoLetterCodes[code]; ok {
+		return short
+	}
+	return code
+}
+
+func IsTextCodec(codec string) bool {
+	_, image := imageCodecs[strings.ToLower(codec)]
+	return !image
+}
+
+// OutputFormat picks the file extension a stream is fetched as. An explicit
+// preferred format wins; otherwise the source format is kept when it is one
+// of srt, vtt or ass and srt is used for everything else.
+func OutputFormat(codec, preferred string) (string, error) {
+	if preferred != "" {
+		format, ok := formats[strings.ToLower(preferred)]
+		if !ok {
+			return "", fmt.Errorf("unsupported subtitle format: %s (use srt, vtt or ass)", preferred)
+		}
+		return format, nil
+	}
+	if format, ok := formats[strings.ToLower(codec)]; ok {
+		return format, nil
+	}
+	return "srt", nil
+}
+
+// FileName builds "<base>.<lang>[.forced][.sdh].<ext>".
+func FileName(base, language string, forced, sdh bool, ext string) string {
+	parts := []string{base, NormalizeLanguage(language)}
+	if forced {
+		parts = append(parts, "forced")
+	}
+	if sdh {
+		parts = append(parts, "sdh")
+	}
+	parts = append(parts, strings.TrimPrefix(ext, "."))
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,89 @@
+package subtitles
+
+import "testing"
+
+func TestParseSelection(t *testing.T) {
+	sel, err := ParseSelection("en, ger")
+	if err != nil {
+		t.Fatalf("ParseSelection: %v", err)
+	}
+	if !sel.Enabled() || sel.All {
+		t.Fatalf("unexpected selection: %+v", sel)
+	}
+	if !sel.Matches("eng") || !sel.Matches("deu") || !sel.Matches("de") {
+		t.Fatalf("expected en/de matches for %+v", sel)
+	}
+	if sel.Matches("fre") {
+		t.Fatalf("did not expect fre to match %+v", sel)
+	}
+
+	all, err := ParseSelection("all")
+	if err != nil || !all.All || !all.Matches("") {
+		t.Fatalf("expected all selection, got %+v err=%v", all, err)
+	}
+
+	none, err := ParseSelection("")
+	if err != nil || none.Enabled() {
+		t.Fatalf("expected empty selection, got %+v err=%v", none, err)
+	}
+
+	if _, err := ParseSelection("english"); err == nil {
+		t.Fatalf("expected error for invalid language")
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	cases := []struct {
+		codec     string
+		preferred string
+		want      string
+		expectOK  bool
+	}{
+		{codec: "subrip", want: "srt", expectOK: true},
+		{codec: "ssa", want: "ass", expectOK: true},
+		{codec: "webvtt", want: "vtt", expectOK: true},
+		{codec: "mov_text", want: "srt", expectOK: true},
+		{codec: "ass", preferred: "vtt", want: "vtt", expectOK: true},
+		{codec: "srt", preferred: "sub"},
+	}
+
+	for _, tc := range cases {
+		got, err := OutputFormat(tc.codec, tc.preferred)
+		if tc.expectOK {
+			if err != nil || got != tc.want {
+				t.Fatalf("OutputFormat(%q, %q) = %q, %v; want %q", tc.codec, tc.preferred, got, err, tc.want)
+			}
+		} else if err == nil {
+			t.Fatalf("OutputFormat(%q, %q) expected error", tc.codec, tc.preferred)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	cases := []struct {
+		lang   string
+		forced bool
+		sdh    bool
+		want   string
+	}{
+		{lang: "eng", want: "Movie (2024).en.srt"},
+		{lang: "ger", forced: true, want: "Movie (2024).de.forced.srt"},
+		{lang: "eng", forced: true, sdh: true, want: "Movie (2024).en.forced.sdh.srt"},
+		{lang: "", want: "Movie (2024).und.srt"},
+	}
+
+	for _, tc := range cases {
+		if got := FileName("Movie (2024)", tc.lang, tc.forced, tc.sdh, ".srt"); got != tc.want {
+			t.Fatalf("FileName(%q) = %q, want %q", tc.lang, got, tc.want)
+		}
+	}
+}
+
+func TestIsTextCodec(t *testing.T) {
+	if IsTextCodec("PGSSUB") || IsTextCodec("dvd_subtitle") {
+		t.Fatalf("expected image codecs to be rejected")
+	}
+	if !IsTextCodec("subrip") {
+		t.Fatalf("expected subrip to be a text codec")
+	}
+}
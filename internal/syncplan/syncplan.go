@@ -0,0 +1,90 @@
+package syncplan
+
+import (
+	"sort"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+)
+
+// Apply narrows the items a rule selected on the server with the rule's
+// unplayed flag and retention settings. keep_latest keeps the last episodes
+// in season order, or the most recently added items for other types.
+func Apply(items []api.Item, rule config.SyncRule, now time.Time) []api.Item {
+	r := rule.Retention
+	var out []api.Item
+	for _, item := range items {
+		played := item.UserData != nil && item.UserData.Played
+		if played && (rule.Unplayed || (r.DeleteWatched != nil && *r.DeleteWatched)) {
+			continue
+		}
+		if r.MaxAgeDays > 0 {
+			created, err := time.Parse(time.RFC3339Nano, item.DateCreated)
+			if err == nil && now.Sub(created) > time.Duration(r.MaxAgeDays)*24*time.Hour {
+				continue
+			}
+		}
+		out = append(out, item)
+	}
+
+	if r.KeepLatest > 0 && len(out) > r.KeepLatest {
+		sorted := append([]api.Item(nil), out...)
+		sort.SliceStable(sorted, func(i, j int) bool { return newer(sorted[i], sorted[j]) })
+		keep := map[string]bool{}
+		for _, item := range sorted[:r.KeepLatest] {
+			keep[item.Id] = true
+		}
+		var kept []api.Item
+		for _, item := range out {
+			if keep[item.Id] {
+				kept = append(kept, item)
+			}
+		}
+		out = kept
+	}
+	return out
+}
+
+func newer(a, b api.Item) bool {
+	if a.Type == "Episode" && b.Type == "Episode" {
+		if a.ParentIndexNumber != b.ParentIndexNumber {
+			return a.ParentIndexNumber > b.ParentIndexNumber
+		}
+		return a.IndexNumber > b.IndexNumber
+	}
+	return a.DateCreated > b.DateCreated
+}
+
+// Stale returns the tracked items whose rule no longer selects them, and
+// the IDs of those items that no rule selects anymore, whose local copies
+// can be deleted. selected maps rule keys to the selected item IDs; the
+// items of rules in skip (e.g. rules that failed to resolve) are kept.
+// Items of rules in keepFiles are reported stale but never deleted.
+func Stale(tracked []store.SyncItem, selected map[string]map[string]bool, skip, keepFiles map[string]bool) ([]store.SyncItem, map[string]bool) {
+	keep := map[string]bool{}
+	for _, ids := range selected {
+		for id := range ids {
+			keep[id] = true
+		}
+	}
+	for _, item := range tracked {
+		if skip[item.Rule] || keepFiles[item.Rule] {
+			keep[item.ItemID] = true
+		}
+	}
+
+	var stale []store.SyncItem
+	remove := map[string]bool{}
+	for _, item := range tracked {
+		if skip[item.Rule] || selected[item.Rule][item.ItemID] {
+			continue
+		}
+		stale = append(stale, item)
+		if !keep[item.ItemID] {
+			remove[item.ItemID] = true
+		}
+	}
+	return stale, remove
+}
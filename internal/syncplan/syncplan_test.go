@@ -0,0 +1,86 @@
+package syncplan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/config"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+)
+
+func ids(items []api.Item) string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Id)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	yes := true
+	episodes := []api.Item{
+		{Id: "e1", Type: "Episode", ParentIndexNumber: 1, IndexNumber: 1, UserData: &api.UserData{Played: true}},
+		{Id: "e2", Type: "Episode", ParentIndexNumber: 1, IndexNumber: 2},
+		{Id: "e3", Type: "Episode", ParentIndexNumber: 2, IndexNumber: 1},
+		{Id: "e4", Type: "Episode", ParentIndexNumber: 2, IndexNumber: 2},
+	}
+	movies := []api.Item{
+		{Id: "old", Type: "Movie", DateCreated: "2024-01-01T00:00:00.0000000Z"},
+		{Id: "new", Type: "Movie", DateCreated: "2024-05-30T12:00:00.0000000Z"},
+		{Id: "mid", Type: "Movie", DateCreated: "2024-05-20T12:00:00.0000000Z"},
+	}
+
+	cases := []struct {
+		items []api.Item
+		rule  config.SyncRule
+		want  string
+	}{
+		{episodes, config.SyncRule{}, "e1,e2,e3,e4"},
+		{episodes, config.SyncRule{Unplayed: true}, "e2,e3,e4"},
+		{episodes, config.SyncRule{Retention: config.Retention{DeleteWatched: &yes}}, "e2,e3,e4"},
+		{episodes, config.SyncRule{Retention: config.Retention{KeepLatest: 2}}, "e3,e4"},
+		{movies, config.SyncRule{Retention: config.Retention{MaxAgeDays: 30}}, "new,mid"},
+		{movies, config.SyncRule{Retention: config.Retention{KeepLatest: 1}}, "new"},
+	}
+	for i, tc := range cases {
+		if got := ids(Apply(tc.items, tc.rule, now)); got != tc.want {
+			t.Fatalf("case %d: Apply = %s, want %s", i, got, tc.want)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	tracked := []store.SyncItem{
+		{Rule: "a", ItemID: "1"},
+		{Rule: "a", ItemID: "2"},
+		{Rule: "b", ItemID: "2"},
+		{Rule: "b", ItemID: "3"},
+		{Rule: "gone", ItemID: "4"},
+		{Rule: "failed", ItemID: "5"},
+		{Rule: "a", ItemID: "5"},
+	}
+	selected := map[string]map[string]bool{
+		"a": {"1": true},
+		"b": {"3": true},
+	}
+	stale, remove := Stale(tracked, selected, map[string]bool{"failed": true}, nil)
+
+	var got []string
+	for _, item := range stale {
+		got = append(got, item.Rule+"/"+item.ItemID)
+	}
+	if strings.Join(got, ",") != "a/2,b/2,gone/4,a/5" {
+		t.Fatalf("stale = %v", got)
+	}
+	if len(remove) != 2 || !remove["2"] || !remove["4"] {
+		t.Fatalf("remove = %v", remove)
+	}
+
+	stale, remove = Stale(tracked, selected, map[string]bool{"failed": true}, map[string]bool{"gone": true})
+	if len(stale) != 4 || len(remove) != 1 || !remove["2"] {
+		t.Fatalf("with keep: stale = %v, remove = %v", stale, remove)
+	}
+}
@@ -0,0 +1,205 @@
+package transcode
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Profile struct {
+	Name          string
+	Container     string
+	VideoCodec    string
+	AudioCodec    string
+	MaxBitrate    int64
+	MaxWidth      int
+	MaxHeight     int
+	AudioLanguage string
+}
+
+var presets = map[string]Profile{
+	"mobile-480p": {
+		Name:       "mobile-480p",
+		Container:  "mp4",
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+		MaxBitrate: 1500000,
+		MaxHeight:  480,
+	},
+	"mobile-720p": {
+		Name:       "mobile-720p",
+		Container:  "mp4",
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+		MaxBitrate: 3000000,
+		MaxHeight:  720,
+	},
+	"tablet-1080p": {
+		Name:       "tablet-1080p",
+		Container:  "mp4",
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+		MaxBitrate: 8000000,
+		MaxHeight:  1080,
+	},
+	"hevc-1080p": {
+		Name:       "hevc-1080p",
+		Container:  "mkv",
+		VideoCodec: "hevc",
+		AudioCodec: "aac",
+		MaxBitrate: 5000000,
+		MaxHeight:  1080,
+	},
+}
+
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup resolves a preset name or a custom spec such as
+// "container=mp4,video=h264,bitrate=3M,height=720".
+func Lookup(value string) (Profile, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Profile{}, fmt.Errorf("empty profile")
+	}
+	if p, ok := presets[strings.ToLower(value)]; ok {
+		return p, nil
+	}
+	if !strings.Contains(value, "=") {
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", value, strings.Join(PresetNames(), ", "))
+	}
+	return parseSpec(value)
+}
+
+func parseSpec(spec string) (Profile, error) {
+	p := Profile{Container: "mp4", VideoCodec: "h264", AudioCodec: "aac"}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Profile{}, fmt.Errorf("invalid profile setting %q", part)
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		val := strings.TrimSpace(kv[1])
+		var err error
+		switch key {
+		case "container":
+			p.Container = strings.ToLower(val)
+		case "video":
+			p.VideoCodec = strings.ToLower(val)
+		case "audio":
+			p.AudioCodec = strings.ToLower(val)
+		case "bitrate":
+			p.MaxBitrate, err = ParseBitrate(val)
+		case "width":
+			p.MaxWidth, err = strconv.Atoi(val)
+		case "height":
+			p.MaxHeight, err = strconv.Atoi(val)
+		case "lang":
+			p.AudioLanguage = strings.ToLower(val)
+		default:
+			return Profile{}, fmt.Errorf("unknown profile setting %q", key)
+		}
+		if err != nil {
+			return Profile{}, fmt.Errorf("invalid profile setting %q: %w", part, err)
+		}
+	}
+	return p, nil
+}
+
+// String returns the preset name when the profile is an unmodified preset
+// and a spec accepted by Lookup otherwise.
+func (p Profile) String() string {
+	if preset, ok := presets[p.Name]; ok && preset == p {
+		return p.Name
+	}
+	parts := []string{
+		"container=" + p.Container,
+		"video=" + p.VideoCodec,
+		"audio=" + p.AudioCodec,
+	}
+	if p.MaxBitrate > 0 {
+		parts = append(parts, fmt.Sprintf("bitrate=%d", p.MaxBitrate))
+	}
+	if p.MaxWidth > 0 {
+		parts = append(parts, fmt.Sprintf("width=%d", p.MaxWidth))
+	}
+	if p.MaxHeight > 0 {
+		parts = append(parts, fmt.Sprintf("height=%d", p.MaxHeight))
+	}
+	if p.AudioLanguage != "" {
+		parts = append(parts, "lang="+p.AudioLanguage)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (p Profile) Extension() string {
+	if p.Container == "" {
+		return ".mp4"
+	}
+	return "." + strings.TrimPrefix(p.Container, ".")
+}
+
+// ParseBitrate parses bits per second values like "4M", "800k" or "2500000".
+func ParseBitrate(value string) (int64, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	value = strings.TrimSuffix(value, "bps")
+	multiplier := float64(1)
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier = 1000
+		value = strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		multiplier = 1000 * 1000
+		value = strings.TrimSuffix(value, "m")
+	case strings.HasSuffix(value, "g"):
+		multiplier = 1000 * 1000 * 1000
+		value = strings.TrimSuffix(value, "g")
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bitrate: %s", value)
+	}
+	if v <= 0 {
+		return 0, fmt.Errorf("bitrate must be > 0")
+	}
+	return int64(v * multiplier), nil
+}
+
+// ParseResolution accepts "720p", "1080p", "4k" or "1280x720" and returns the
+// maximum width (0 when unspecified) and height.
+func ParseResolution(value string) (int, int, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	switch value {
+	case "4k", "uhd":
+		return 3840, 2160, nil
+	case "fhd":
+		return 1920, 1080, nil
+	case "hd":
+		return 1280, 720, nil
+	}
+	if strings.Contains(value, "x") {
+		parts := strings.SplitN(value, "x", 2)
+		w, err1 := strconv.Atoi(parts[0])
+		h, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || w <= 0 || h <= 0 {
+			return 0, 0, fmt.Errorf("invalid resolution: %s", value)
+		}
+		return w, h, nil
+	}
+	h, err := strconv.Atoi(strings.TrimSuffix(value, "p"))
+	if err != nil || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid resolution: %s", value)
+	}
+	return 0, h, nil
+}
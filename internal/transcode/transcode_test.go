@@ -0,0 +1,85 @@
+package transcode
+
+import "testing"
+
+func TestLookupPreset(t *testing.T) {
+	p, err := Lookup("Mobile-720p")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if p.Container != "mp4" || p.MaxHeight != 720 {
+		t.Fatalf("unexpected preset: %+v", p)
+	}
+	if p.String() != "mobile-720p" {
+		t.Fatalf("expected preset name, got %q", p.String())
+	}
+
+	p.MaxBitrate = 2000000
+	round, err := Lookup(p.String())
+	if err != nil {
+		t.Fatalf("Lookup(%q): %v", p.String(), err)
+	}
+	round.Name = p.Name
+	if round != p {
+		t.Fatalf("custom profile did not round-trip: %+v vs %+v", round, p)
+	}
+
+	if _, err := Lookup("potato"); err == nil {
+		t.Fatalf("expected unknown preset error")
+	}
+	if _, err := Lookup("container=mp4,speed=9"); err == nil {
+		t.Fatalf("expected unknown setting error")
+	}
+}
+
+func TestParseBitrate(t *testing.T) {
+	cases := []struct {
+		in       string
+		want     int64
+		expectOK bool
+	}{
+		{in: "4M", want: 4000000, expectOK: true},
+		{in: "800k", want: 800000, expectOK: true},
+		{in: "2500000", want: 2500000, expectOK: true},
+		{in: "1.5Mbps", want: 1500000, expectOK: true},
+		{in: "0"},
+		{in: "fast"},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseBitrate(tc.in)
+		if tc.expectOK {
+			if err != nil || got != tc.want {
+				t.Fatalf("ParseBitrate(%q) = %d, %v; want %d", tc.in, got, err, tc.want)
+			}
+		} else if err == nil {
+			t.Fatalf("ParseBitrate(%q) expected error", tc.in)
+		}
+	}
+}
+
+func TestParseResolution(t *testing.T) {
+	cases := []struct {
+		in       string
+		w, h     int
+		expectOK bool
+	}{
+		{in: "720p", h: 720, expectOK: true},
+		{in: "1080", h: 1080, expectOK: true},
+		{in: "4k", w: 3840, h: 2160, expectOK: true},
+		{in: "1280x720", w: 1280, h: 720, expectOK: true},
+		{in: "x720"},
+		{in: "big"},
+	}
+
+	for _, tc := range cases {
+		w, h, err := ParseResolution(tc.in)
+		if tc.expectOK {
+			if err != nil || w != tc.w || h != tc.h {
+				t.Fatalf("ParseResolution(%q) = %d, %d, %v; want %d, %d", tc.in, w, h, err, tc.w, tc.h)
+			}
+		} else if err == nil {
+			t.Fatalf("ParseResolution(%q) expected error", tc.in)
+		}
+	}
+}
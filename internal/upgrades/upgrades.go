@@ -0,0 +1,75 @@
+package upgrades
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+)
+
+// Check compares a finished download with the item's current media sources.
+// It returns why the download is outdated ("" when it is current) and the
+// index of the media source to download the replacement from.
+//
+// Downloads that recorded their source are compared by source ID, ETag and
+// size. Older records only have the downloaded size, which is compared with
+// the source of the downloaded version unless the download was transcoded.
+// When the recorded source is gone, the replacement is the source with the
+// downloaded version's name; downloads of a version that no longer exists
+// are left alone.
+func Check(d store.Download, item api.Item) (string, int) {
+	if len(item.MediaSources) == 0 {
+		return "", 0
+	}
+	if !d.SourceID.Valid {
+		idx, ok := versionIndex(d, item.MediaSources)
+		if !ok || d.Profile.Valid || !d.BytesTotal.Valid || d.BytesTotal.Int64 <= 0 {
+			return "", 0
+		}
+		if size := item.MediaSources[idx].Size; size > 0 && size != d.BytesTotal.Int64 {
+			return sizeChanged(d.BytesTotal.Int64, size), idx
+		}
+		return "", 0
+	}
+
+	idx := -1
+	for i, src := range item.MediaSources {
+		if src.Id == d.SourceID.String {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		if idx, ok := versionIndex(d, item.MediaSources); ok {
+			return "media source replaced", idx
+		}
+		return "", 0
+	}
+	src := item.MediaSources[idx]
+	switch {
+	case d.SourceETag.Valid && src.ETag != "" && src.ETag != d.SourceETag.String:
+		return "file modified on server", idx
+	case d.SourceSize.Valid && src.Size > 0 && src.Size != d.SourceSize.Int64:
+		return sizeChanged(d.SourceSize.Int64, src.Size), idx
+	}
+	return "", idx
+}
+
+// versionIndex finds the source of the version that was downloaded. A
+// download without a version name was made from the item's only source.
+func versionIndex(d store.Download, sources []api.MediaSource) (int, bool) {
+	if !d.Version.Valid {
+		return 0, true
+	}
+	for i, src := range sources {
+		if strings.EqualFold(src.Name, d.Version.String) || src.Id == d.Version.String {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func sizeChanged(before, after int64) string {
+	return fmt.Sprintf("size changed from %d to %d bytes", before, after)
+}
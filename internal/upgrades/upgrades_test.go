@@ -0,0 +1,60 @@
+package upgrades
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+	"github.com/julianfbeck/jellyfin-download-cli/internal/store"
+)
+
+func TestCheck(t *testing.T) {
+	item := api.Item{Id: "movie", MediaSources: []api.MediaSource{
+		{Id: "a", Name: "4K", Size: 100, ETag: "e1"},
+		{Id: "b", Name: "1080p", Size: 200, ETag: "e2"},
+	}}
+	version := func(d store.Download, name string) store.Download {
+		d.Version = sql.NullString{String: name, Valid: true}
+		return d
+	}
+	source := func(id string, size int64, etag string) store.Download {
+		return store.Download{
+			SourceID:   sql.NullString{String: id, Valid: true},
+			SourceSize: sql.NullInt64{Int64: size, Valid: true},
+			SourceETag: sql.NullString{String: etag, Valid: etag != ""},
+		}
+	}
+
+	cases := []struct {
+		name   string
+		d      store.Download
+		reason string
+		idx    int
+	}{
+		{"current", source("b", 200, "e2"), "", 1},
+		{"etag", source("b", 200, "old"), "file modified on server", 1},
+		{"size", source("a", 90, ""), "size changed from 90 to 100 bytes", 0},
+		{"replaced", source("gone", 200, "e2"), "media source replaced", 0},
+		{"replaced version", version(source("gone", 200, "e2"), "1080p"), "media source replaced", 1},
+		{"replaced missing version", version(source("gone", 200, "e2"), "720p"), "", 0},
+		{"legacy size", store.Download{BytesTotal: sql.NullInt64{Int64: 50, Valid: true}}, "size changed from 50 to 100 bytes", 0},
+		{"legacy same", store.Download{BytesTotal: sql.NullInt64{Int64: 100, Valid: true}}, "", 0},
+		{"legacy version", version(store.Download{BytesTotal: sql.NullInt64{Int64: 200, Valid: true}}, "1080p"), "", 0},
+		{"legacy version size", version(store.Download{BytesTotal: sql.NullInt64{Int64: 150, Valid: true}}, "1080p"), "size changed from 150 to 200 bytes", 1},
+		{"legacy missing version", version(store.Download{BytesTotal: sql.NullInt64{Int64: 150, Valid: true}}, "720p"), "", 0},
+		{"legacy transcode", store.Download{
+			BytesTotal: sql.NullInt64{Int64: 50, Valid: true},
+			Profile:    sql.NullString{String: "mobile-720p", Valid: true},
+		}, "", 0},
+	}
+	for _, tc := range cases {
+		reason, idx := Check(tc.d, item)
+		if reason != tc.reason || idx != tc.idx {
+			t.Fatalf("%s: Check = %q, %d; want %q, %d", tc.name, reason, idx, tc.reason, tc.idx)
+		}
+	}
+
+	if reason, _ := Check(source("a", 1, "x"), api.Item{}); reason != "" {
+		t.Fatalf("expected no upgrade without media sources, got %q", reason)
+	}
+}
@@ -0,0 +1,263 @@
+package versions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+// Selector picks one media source out of an item's alternate versions. It is
+// built from a --version value, which is either a 1-based index, a version
+// name, an exact media source ("id=<sourceId>") or preference rules such as
+// "prefer=1080p,sdr max-size=8G".
+type Selector struct {
+	ID            string
+	Index         int
+	Name          string
+	Prefer        []string
+	Avoid         []string
+	MaxSize       int64
+	MaxResolution int
+}
+
+func Parse(value string) (*Selector, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if idx, err := strconv.Atoi(value); err == nil {
+		if idx < 1 {
+			return nil, fmt.Errorf("version index must be >= 1")
+		}
+		return &Selector{Index: idx}, nil
+	}
+	if !strings.Contains(value, "=") {
+		return &Selector{Name: value}, nil
+	}
+
+	sel := &Selector{}
+	for _, rule := range strings.Fields(value) {
+		kv := strings.SplitN(rule, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid version rule %q", rule)
+		}
+		key := strings.ToLower(kv[0])
+		val := strings.ToLower(kv[1])
+		switch key {
+		case "id":
+			sel.ID = kv[1]
+		case "prefer":
+			sel.Prefer = append(sel.Prefer, splitTraits(val)...)
+		case "avoid":
+			sel.Avoid = append(sel.Avoid, splitTraits(val)...)
+		case "max-size":
+			size, err := ParseSize(val)
+			if err != nil {
+				return nil, err
+			}
+			sel.MaxSize = size
+		case "max-resolution":
+			height, err := resolutionHeight(val)
+			if err != nil {
+				return nil, err
+			}
+			sel.MaxResolution = height
+		default:
+			return nil, fmt.Errorf("unknown version rule %q (use id, prefer, avoid, max-size, max-resolution)", key)
+		}
+	}
+	return sel, nil
+}
+
+// String returns a value that Parse turns back into an equivalent selector.
+func (s *Selector) String() string {
+	if s == nil {
+		return ""
+	}
+	if s.Index > 0 {
+		return strconv.Itoa(s.Index)
+	}
+	if s.Name != "" {
+		return s.Name
+	}
+	var rules []string
+	if s.ID != "" {
+		rules = append(rules, "id="+s.ID)
+	}
+	if len(s.Prefer) > 0 {
+		rules = append(rules, "prefer="+strings.Join(s.Prefer, ","))
+	}
+	if len(s.Avoid) > 0 {
+		rules = append(rules, "avoid="+strings.Join(s.Avoid, ","))
+	}
+	if s.MaxSize > 0 {
+		rules = append(rules, fmt.Sprintf("max-size=%d", s.MaxSize))
+	}
+	if s.MaxResolution > 0 {
+		rules = append(rules, fmt.Sprintf("max-resolution=%dp", s.MaxResolution))
+	}
+	return strings.Join(rules, " ")
+}
+
+// Select returns the index of the chosen source.
+func (s *Selector) Select(sources []api.MediaSource) (int, error) {
+	if len(sources) == 0 {
+		return 0, fmt.Errorf("item has no media sources")
+	}
+	if s == nil {
+		return 0, nil
+	}
+	if s.ID != "" {
+		for i, src := range sources {
+			if src.Id == s.ID {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("version %s no longer exists", s.ID)
+	}
+	if s.Index > 0 {
+		if s.Index > len(sources) {
+			return 0, fmt.Errorf("version %d not found (item has %d)", s.Index, len(sources))
+		}
+		return s.Index - 1, nil
+	}
+	if s.Name != "" {
+		return selectByName(sources, s.Name)
+	}
+
+	var candidates []int
+	for i, src := range sources {
+		if s.MaxSize > 0 && src.Size > s.MaxSize {
+			continue
+		}
+		if s.MaxResolution > 0 {
+			if h, err := resolutionHeight(src.Resolution()); err == nil && h > s.MaxResolution {
+				continue
+			}
+		}
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("no version matches %q", s.String())
+	}
+
+	scores := make(map[int]int, len(candidates))
+	for _, i := range candidates {
+		traits := Traits(sources[i])
+		for rank, want := range s.Prefer {
+			if traits[want] {
+				scores[i] += len(s.Prefer) - rank
+			}
+		}
+		for _, avoid := range s.Avoid {
+			if traits[avoid] {
+				scores[i] -= len(s.Prefer) + 1
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		ia, ib := candidates[a], candidates[b]
+		if scores[ia] != scores[ib] {
+			return scores[ia] > scores[ib]
+		}
+		return sources[ia].Size > sources[ib].Size
+	})
+	return candidates[0], nil
+}
+
+// Traits returns the lowercase labels a source can be matched against:
+// resolution, HDR type (plus "hdr" for any HDR format), codecs and container.
+func Traits(src api.MediaSource) map[string]bool {
+	traits := map[string]bool{}
+	add := func(v string) {
+		if v != "" {
+			traits[strings.ToLower(v)] = true
+		}
+	}
+	res := src.Resolution()
+	add(res)
+	if res == "2160p" {
+		add("4k")
+	}
+	hdr := src.HDRType()
+	if hdr == "" {
+		hdr = "sdr"
+	}
+	add(hdr)
+	if hdr != "sdr" {
+		add("hdr")
+	}
+	add(src.VideoCodec())
+	if src.VideoCodec() == "hevc" {
+		add("h265")
+	}
+	add(src.AudioCodec())
+	add(src.Container)
+	return traits
+}
+
+func selectByName(sources []api.MediaSource, name string) (int, error) {
+	for i, src := range sources {
+		if strings.EqualFold(src.Name, name) {
+			return i, nil
+		}
+	}
+	lower := strings.ToLower(name)
+	for i, src := range sources {
+		if strings.Contains(strings.ToLower(src.Name), lower) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("version %q not found", name)
+}
+
+func splitTraits(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func resolutionHeight(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "4k" {
+		return 2160, nil
+	}
+	h, err := strconv.Atoi(strings.TrimSuffix(value, "p"))
+	if err != nil || h <= 0 {
+		return 0, fmt.Errorf("invalid resolution: %s", value)
+	}
+	return h, nil
+}
+
+// ParseSize parses byte sizes such as "8G", "700M" or "1.5GB" (binary units).
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+	multiplier := float64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	case strings.HasSuffix(value, "T"):
+		multiplier = 1 << 40
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(v * multiplier), nil
+}
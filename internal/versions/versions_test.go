@@ -0,0 +1,120 @@
+package versions
+
+import (
+	"testing"
+
+	"github.com/julianfbeck/jellyfin-download-cli/internal/api"
+)
+
+func testSources() []api.MediaSource {
+	return []api.MediaSource{
+		{
+			Id:        "uhd",
+			Name:      "4K HDR Remux",
+			Container: "mkv",
+			Size:      60 << 30,
+			MediaStreams: []api.MediaStream{
+				{Type: "Video", Codec: "hevc", Width: 3840, Height: 2160, VideoRangeType: "DOVIWithHDR10"},
+				{Type: "Audio", Codec: "truehd"},
+			},
+		},
+		{
+			Id:        "fhd",
+			Name:      "1080p",
+			Container: "mkv",
+			Size:      6 << 30,
+			MediaStreams: []api.MediaStream{
+				{Type: "Video", Codec: "h264", Width: 1920, Height: 800, VideoRangeType: "SDR"},
+				{Type: "Audio", Codec: "ac3"},
+			},
+		},
+		{
+			Id:        "hd",
+			Name:      "720p Mobile",
+			Container: "mp4",
+			Size:      2 << 30,
+			MediaStreams: []api.MediaStream{
+				{Type: "Video", Codec: "h264", Width: 1280, Height: 720, VideoRangeType: "SDR"},
+			},
+		},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	sources := testSources()
+	cases := []struct {
+		spec     string
+		want     string
+		expectOK bool
+	}{
+		{spec: "", want: "uhd", expectOK: true},
+		{spec: "2", want: "fhd", expectOK: true},
+		{spec: "mobile", want: "hd", expectOK: true},
+		{spec: "4K HDR Remux", want: "uhd", expectOK: true},
+		{spec: "prefer=1080p,sdr max-size=8G", want: "fhd", expectOK: true},
+		{spec: "prefer=hdr", want: "uhd", expectOK: true},
+		{spec: "prefer=sdr avoid=mkv", want: "hd", expectOK: true},
+		{spec: "max-resolution=720p", want: "hd", expectOK: true},
+		{spec: "id=fhd", want: "fhd", expectOK: true},
+		{spec: "id=gone"},
+		{spec: "max-size=1G"},
+		{spec: "7"},
+		{spec: "director's cut"},
+	}
+
+	for _, tc := range cases {
+		sel, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.spec, err)
+		}
+		idx, err := sel.Select(sources)
+		if tc.expectOK {
+			if err != nil {
+				t.Fatalf("Select(%q) unexpected error: %v", tc.spec, err)
+			}
+			if got := sources[idx].Id; got != tc.want {
+				t.Fatalf("Select(%q) = %s, want %s", tc.spec, got, tc.want)
+			}
+		} else if err == nil {
+			t.Fatalf("Select(%q) expected error, got %s", tc.spec, sources[idx].Id)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, spec := range []string{"2", "Remux", "id=hd", "prefer=1080p,sdr avoid=hevc max-size=8G max-resolution=1080p"} {
+		sel, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		again, err := Parse(sel.String())
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sel.String(), err)
+		}
+		if again.String() != sel.String() {
+			t.Fatalf("round trip mismatch: %q vs %q", again.String(), sel.String())
+		}
+	}
+
+	for _, bad := range []string{"0", "prefer=", "speed=fast", "max-size=lots"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("Parse(%q) expected error", bad)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"8G":    8 << 30,
+		"700M":  700 << 20,
+		"1.5GB": 3 << 29,
+		"2GiB":  2 << 30,
+		"512":   512,
+	}
+	for in, want := range cases {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxBody = 1 << 20
+
+// Event is the part of a Jellyfin Webhook plugin payload that is used. The
+// plugin's default templates send these fields; custom templates must keep
+// NotificationType and ItemId.
+type Event struct {
+	NotificationType string `json:"NotificationType"`
+	ItemId           string `json:"ItemId"`
+	ItemType         string `json:"ItemType"`
+	Name             string `json:"Name"`
+}
+
+// Authorized reports whether r carries secret in the X-Webhook-Secret
+// header, as a bearer token, or in the "secret" query parameter.
+func Authorized(r *http.Request, secret string) bool {
+	candidates := []string{
+		r.Header.Get("X-Webhook-Secret"),
+		strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		r.URL.Query().Get("secret"),
+	}
+	for _, c := range candidates {
+		if c != "" && subtle.ConstantTimeCompare([]byte(c), []byte(secret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler accepts webhook POSTs authorized with secret and calls handle for
+// every ItemAdded event before answering 202 Accepted, so handle should only
+// record the event. Other notification types are acknowledged and ignored.
+func Handler(secret string, handle func(Event)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !Authorized(r, secret) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			http.Error(w, "reading body failed", http.StatusBadRequest)
+			return
+		}
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			http.Error(w, "invalid JSON payload", http.StatusBadRequest)
+			return
+		}
+		if event.NotificationType != "ItemAdded" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if event.ItemId == "" {
+			http.Error(w, "missing ItemId", http.StatusBadRequest)
+			return
+		}
+		handle(event)
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
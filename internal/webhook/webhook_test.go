@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var got []Event
+	h := Handler("s3cret", func(e Event) { got = append(got, e) })
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		header map[string]string
+		body   string
+		status int
+	}{
+		{"wrong method", http.MethodGet, "/", nil, "", http.StatusMethodNotAllowed},
+		{"no secret", http.MethodPost, "/", nil, `{"NotificationType":"ItemAdded","ItemId":"x"}`, http.StatusUnauthorized},
+		{"wrong secret", http.MethodPost, "/", map[string]string{"X-Webhook-Secret": "nope"}, `{}`, http.StatusUnauthorized},
+		{"bad json", http.MethodPost, "/?secret=s3cret", nil, `{`, http.StatusBadRequest},
+		{"other event", http.MethodPost, "/", map[string]string{"Authorization": "Bearer s3cret"}, `{"NotificationType":"PlaybackStart","ItemId":"x"}`, http.StatusNoContent},
+		{"missing item", http.MethodPost, "/", map[string]string{"X-Webhook-Secret": "s3cret"}, `{"NotificationType":"ItemAdded"}`, http.StatusBadRequest},
+		{"item added", http.MethodPost, "/", map[string]string{"X-Webhook-Secret": "s3cret"},
+			`{"NotificationType":"ItemAdded","ItemId":"abc","ItemType":"Episode","Name":"Pilot","ServerName":"home"}`, http.StatusAccepted},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: status %d, want %d", tc.name, rec.Code, tc.status)
+		}
+	}
+	if len(got) != 1 || got[0].ItemId != "abc" || got[0].ItemType != "Episode" {
+		t.Fatalf("unexpected events: %+v", got)
+	}
+}